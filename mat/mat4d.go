@@ -0,0 +1,147 @@
+package mat
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Mat4d is a float64 version of Mat4 stored by (column * 4 + row) index.
+type Mat4d [16]float64
+
+// Mat4d converts Mat4 to Mat4d.
+func (m Mat4) Mat4d() Mat4d {
+	var out Mat4d
+	for i := range m {
+		out[i] = float64(m[i])
+	}
+	return out
+}
+
+// Mat4 converts Mat4d to Mat4.
+func (m Mat4d) Mat4() Mat4 {
+	var out Mat4
+	for i := range m {
+		out[i] = float32(m[i])
+	}
+	return out
+}
+
+func TranslateD(x, y, z float64) Mat4d {
+	return Mat4d{
+		1, 0, 0, 0,
+		0, 1, 0, 0,
+		0, 0, 1, 0,
+		x, y, z, 1,
+	}
+}
+
+func (m Mat4d) Floats() [16]float64 {
+	return m
+}
+
+func (m Mat4d) Mul(a Mat4d) Mat4d {
+	var out Mat4d
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			var sum float64
+			for k := 0; k < 4; k++ {
+				sum += m[4*k+i] * a[4*j+k]
+			}
+			out[4*j+i] = sum
+		}
+	}
+	return out
+}
+
+func (m Mat4d) Factor(f float64) Mat4d {
+	var out Mat4d
+	for i := range m {
+		out[i] = m[i] * f
+	}
+	return out
+}
+
+func (m Mat4d) Add(a Mat4d) Mat4d {
+	var out Mat4d
+	for i := range m {
+		out[i] = m[i] + a[i]
+	}
+	return out
+}
+
+func (m Mat4d) MulAffine(a Mat4d) Mat4d {
+	var out Mat4d
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 4; j++ {
+			sum := m[4*0+i]*a[4*j+0] + m[4*1+i]*a[4*j+1] + m[4*2+i]*a[4*j+2]
+			if j == 3 {
+				sum += m[4*3+i]
+			}
+			out[4*j+i] = sum
+		}
+	}
+	out[4*3+3] = 1
+	return out
+}
+
+func (m Mat4d) InvAffine() Mat4d {
+	var out Mat4d
+	normInv := 1 / (m[4*0+0]*m[4*1+1]*m[4*2+2] +
+		m[4*0+1]*m[4*1+2]*m[4*2+0] +
+		m[4*0+2]*m[4*1+0]*m[4*2+1] -
+		m[4*0+2]*m[4*1+1]*m[4*2+0] -
+		m[4*0+1]*m[4*1+0]*m[4*2+2] -
+		m[4*0+0]*m[4*1+2]*m[4*2+1])
+
+	out[4*0+0] = (m[4*1+1]*m[4*2+2] - m[4*1+2]*m[4*2+1]) * normInv
+	out[4*0+1] = -(m[4*0+1]*m[4*2+2] - m[4*0+2]*m[4*2+1]) * normInv
+	out[4*0+2] = (m[4*0+1]*m[4*1+2] - m[4*0+2]*m[4*1+1]) * normInv
+	out[4*1+0] = -(m[4*1+0]*m[4*2+2] - m[4*1+2]*m[4*2+0]) * normInv
+	out[4*1+1] = (m[4*0+0]*m[4*2+2] - m[4*0+2]*m[4*2+0]) * normInv
+	out[4*1+2] = -(m[4*0+0]*m[4*1+2] - m[4*0+2]*m[4*1+0]) * normInv
+	out[4*2+0] = (m[4*1+0]*m[4*2+1] - m[4*1+1]*m[4*2+0]) * normInv
+	out[4*2+1] = -(m[4*0+0]*m[4*2+1] - m[4*0+1]*m[4*2+0]) * normInv
+	out[4*2+2] = (m[4*0+0]*m[4*1+1] - m[4*0+1]*m[4*1+0]) * normInv
+	out[4*3+3] = 1
+	b2 := out.TransformAffine(NewVec3d(m[4*3+0], m[4*3+1], m[4*3+2]))
+	out[4*3+0] = -b2[0]
+	out[4*3+1] = -b2[1]
+	out[4*3+2] = -b2[2]
+	return out
+}
+
+func (m Mat4d) TransformAffine(a Vec3d) Vec3d {
+	return Vec3d{
+		m[4*0+0]*a[0] + m[4*1+0]*a[1] + m[4*2+0]*a[2] + m[4*3+0],
+		m[4*0+1]*a[0] + m[4*1+1]*a[1] + m[4*2+1]*a[2] + m[4*3+1],
+		m[4*0+2]*a[0] + m[4*1+2]*a[1] + m[4*2+2]*a[2] + m[4*3+2],
+	}
+}
+
+func (m Mat4d) Transform(a Vec3d) Vec3d {
+	w := 1 / (m[4*0+3]*a[0] + m[4*1+3]*a[1] + m[4*2+3]*a[2] + m[4*3+3])
+	return Vec3d{
+		(m[4*0+0]*a[0] + m[4*1+0]*a[1] + m[4*2+0]*a[2] + m[4*3+0]) * w,
+		(m[4*0+1]*a[0] + m[4*1+1]*a[1] + m[4*2+1]*a[2] + m[4*3+1]) * w,
+		(m[4*0+2]*a[0] + m[4*1+2]*a[1] + m[4*2+2]*a[2] + m[4*3+2]) * w,
+	}
+}
+
+func (m Mat4d) Transpose() Mat4d {
+	return Mat4d{
+		m[4*0+0], m[4*1+0], m[4*2+0], m[4*3+0],
+		m[4*0+1], m[4*1+1], m[4*2+1], m[4*3+1],
+		m[4*0+2], m[4*1+2], m[4*2+2], m[4*3+2],
+		m[4*0+3], m[4*1+3], m[4*2+3], m[4*3+3],
+	}
+}
+
+func (m Mat4d) String() string {
+	out := make([]string, 4)
+	for j := 0; j < 4; j++ {
+		out[j] = fmt.Sprintf("[%0.3f %0.3f %0.3f %0.3f]",
+			m[j*4+0], m[j*4+1], m[j*4+2], m[j*4+3],
+		)
+	}
+	return "[" + strings.Join(out, " ") + "]"
+}
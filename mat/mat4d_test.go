@@ -0,0 +1,67 @@
+package mat
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMat4d_Conversion(t *testing.T) {
+	m := Translate(0.1, 0.2, 0.3).Mul(Rotate(1, 0, 0, 0.5))
+	if m2 := m.Mat4d().Mat4(); !reflect.DeepEqual(m, m2) {
+		t.Errorf("Expected %v, got %v", m, m2)
+	}
+}
+
+func TestMat4d_MulAffine(t *testing.T) {
+	m0 := Translate(0.1, 0.2, 0.3).Mat4d()
+	m1 := Scale(1.1, 1.2, 1.3).Mat4d()
+	m2 := Rotate(1, 0, 0, 0.1).Mat4d()
+
+	r := m0.MulAffine(m1).MulAffine(m2)
+	rNaive := m0.Mul(m1).Mul(m2)
+	for i := range r {
+		if diff := r[i] - rNaive[i]; diff < -1e-9 || 1e-9 < diff {
+			t.Fatalf("Expected %v, got %v", rNaive, r)
+		}
+	}
+}
+
+func TestMat4d_InvAffine(t *testing.T) {
+	m := TranslateD(500000.01, 4000000.02, 10.03).
+		MulAffine(Rotate(0, 0, 1, 0.5).Mat4d())
+
+	p := Vec3d{500010.5, 4000020.25, 12.125}
+	p2 := m.InvAffine().TransformAffine(m.TransformAffine(p))
+	for i := range p {
+		if diff := p2[i] - p[i]; diff < -1e-6 || 1e-6 < diff {
+			t.Fatalf("Expected %v, got %v", p, p2)
+		}
+	}
+
+	// Transform should give the same result for affine matrix.
+	p3 := m.Transform(p)
+	p4 := m.TransformAffine(p)
+	for i := range p3 {
+		if diff := p3[i] - p4[i]; diff < -1e-6 || 1e-6 < diff {
+			t.Fatalf("Expected %v, got %v", p4, p3)
+		}
+	}
+}
+
+func TestMat4d_Transpose(t *testing.T) {
+	m := Mat4d{
+		1, 2, 3, 4,
+		5, 6, 7, 8,
+		9, 10, 11, 12,
+		13, 14, 15, 16,
+	}
+	expected := Mat4d{
+		1, 5, 9, 13,
+		2, 6, 10, 14,
+		3, 7, 11, 15,
+		4, 8, 12, 16,
+	}
+	if trans := m.Transpose(); !reflect.DeepEqual(expected, trans) {
+		t.Errorf("Expected %v, got %v", expected, trans)
+	}
+}
@@ -1,3 +1,71 @@
 package mat
 
+import (
+	"fmt"
+	"strings"
+)
+
+// Mat6 represents 6x6 matrix stored by (column * 6 + row) index.
 type Mat6 [36]float32
+
+func (m Mat6) Mul(a Mat6) Mat6 {
+	var out Mat6
+	for i := 0; i < 6; i++ {
+		for j := 0; j < 6; j++ {
+			var sum float32
+			for k := 0; k < 6; k++ {
+				sum += m[6*k+i] * a[6*j+k]
+			}
+			out[6*j+i] = sum
+		}
+	}
+	return out
+}
+
+func (m Mat6) MulVec(a Vec6) Vec6 {
+	var out Vec6
+	for i := 0; i < 6; i++ {
+		var sum float32
+		for k := 0; k < 6; k++ {
+			sum += m[6*k+i] * a[k]
+		}
+		out[i] = sum
+	}
+	return out
+}
+
+func (m Mat6) Factor(f float32) Mat6 {
+	var out Mat6
+	for i := range m {
+		out[i] = m[i] * f
+	}
+	return out
+}
+
+func (m Mat6) Add(a Mat6) Mat6 {
+	var out Mat6
+	for i := range m {
+		out[i] = m[i] + a[i]
+	}
+	return out
+}
+
+func (m Mat6) Transpose() Mat6 {
+	var out Mat6
+	for i := 0; i < 6; i++ {
+		for j := 0; j < 6; j++ {
+			out[6*j+i] = m[6*i+j]
+		}
+	}
+	return out
+}
+
+func (m Mat6) String() string {
+	out := make([]string, 6)
+	for j := 0; j < 6; j++ {
+		out[j] = fmt.Sprintf("[%0.3f %0.3f %0.3f %0.3f %0.3f %0.3f]",
+			m[j*6+0], m[j*6+1], m[j*6+2], m[j*6+3], m[j*6+4], m[j*6+5],
+		)
+	}
+	return "[" + strings.Join(out, " ") + "]"
+}
@@ -0,0 +1,60 @@
+package mat
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMat6_Mul(t *testing.T) {
+	var m, id Mat6
+	for i := range m {
+		m[i] = float32(i)
+	}
+	for i := 0; i < 6; i++ {
+		id[6*i+i] = 1
+	}
+	if out := m.Mul(id); !reflect.DeepEqual(m, out) {
+		t.Errorf("Expected %v, got %v", m, out)
+	}
+	if out := id.Mul(m); !reflect.DeepEqual(m, out) {
+		t.Errorf("Expected %v, got %v", m, out)
+	}
+
+	v := Vec6{1, 0, 0, 0, 0, 2}
+	// column 0 + 2 * column 5
+	expected := Vec6{
+		0 + 2*30, 1 + 2*31, 2 + 2*32, 3 + 2*33, 4 + 2*34, 5 + 2*35,
+	}
+	if out := m.MulVec(v); !out.Equal(expected) {
+		t.Errorf("Expected %v, got %v", expected, out)
+	}
+}
+
+func TestMat6_Transpose(t *testing.T) {
+	var m Mat6
+	for i := range m {
+		m[i] = float32(i)
+	}
+	mt := m.Transpose()
+	for i := 0; i < 6; i++ {
+		for j := 0; j < 6; j++ {
+			if mt[6*j+i] != m[6*i+j] {
+				t.Fatalf("m(%d, %d) expected to be %0.3f, got %0.3f", i, j, m[6*i+j], mt[6*j+i])
+			}
+		}
+	}
+	if out := mt.Transpose(); !reflect.DeepEqual(m, out) {
+		t.Errorf("Expected %v, got %v", m, out)
+	}
+}
+
+func TestMat6_AddFactor(t *testing.T) {
+	var m Mat6
+	for i := range m {
+		m[i] = float32(i)
+	}
+	out := m.Add(m)
+	if out2 := m.Factor(2); !reflect.DeepEqual(out, out2) {
+		t.Errorf("Expected %v, got %v", out, out2)
+	}
+}
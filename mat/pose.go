@@ -0,0 +1,67 @@
+package mat
+
+// PoseWithCovariance represents rigid transformation with its uncertainty.
+//
+// Covariance is defined on the perturbation left-multiplied to the Pose:
+//
+//	exp(d) * Pose
+//
+// where d is a twist vector ordered as {x, y, z, wx, wy, wz}
+// (same order as the gradient of icp.Evaluated).
+type PoseWithCovariance struct {
+	Pose       Mat4
+	Covariance Mat6
+}
+
+// Adjoint returns 6x6 adjoint matrix of the rigid transformation m.
+// Twist vector d is transformed to m * d^ * m^-1 by the matrix.
+//
+//	Adjoint(m): {
+//	  R  [t]x*R
+//	  0  R
+//	}
+func Adjoint(m Mat4) Mat6 {
+	var out Mat6
+	tx, ty, tz := m[4*3+0], m[4*3+1], m[4*3+2]
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			r := m[4*j+i]
+			out[6*j+i] = r
+			out[6*(j+3)+(i+3)] = r
+		}
+	}
+	// [t]x * R
+	for j := 0; j < 3; j++ {
+		r0, r1, r2 := m[4*j+0], m[4*j+1], m[4*j+2]
+		out[6*(j+3)+0] = ty*r2 - tz*r1
+		out[6*(j+3)+1] = tz*r0 - tx*r2
+		out[6*(j+3)+2] = tx*r1 - ty*r0
+	}
+	return out
+}
+
+// Mul returns composed transformation p.Pose * a.Pose.
+// Uncertainties of p and a are assumed to be independent.
+func (p PoseWithCovariance) Mul(a PoseWithCovariance) PoseWithCovariance {
+	ad := Adjoint(p.Pose)
+	return PoseWithCovariance{
+		Pose:       p.Pose.MulAffine(a.Pose),
+		Covariance: p.Covariance.Add(ad.Mul(a.Covariance).Mul(ad.Transpose())),
+	}
+}
+
+// Inv returns inverse transformation.
+func (p PoseWithCovariance) Inv() PoseWithCovariance {
+	inv := p.Pose.InvAffine()
+	ad := Adjoint(inv)
+	return PoseWithCovariance{
+		Pose:       inv,
+		Covariance: ad.Mul(p.Covariance).Mul(ad.Transpose()),
+	}
+}
+
+// Relative returns relative transformation from p to a, p.Pose^-1 * a.Pose.
+// Uncertainties of p and a are assumed to be independent.
+func (p PoseWithCovariance) Relative(a PoseWithCovariance) PoseWithCovariance {
+	return p.Inv().Mul(a)
+}
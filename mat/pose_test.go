@@ -0,0 +1,123 @@
+package mat
+
+import (
+	"testing"
+)
+
+func hat(v Vec6) Mat4 {
+	return Mat4{
+		0, v[5], -v[4], 0,
+		-v[5], 0, v[3], 0,
+		v[4], -v[3], 0, 0,
+		v[0], v[1], v[2], 0,
+	}
+}
+
+func TestAdjoint(t *testing.T) {
+	m := Translate(0.5, -1.0, 2.0).Mul(Rotate(0, 0.6, 0.8, 0.7))
+	ad := Adjoint(m)
+
+	for _, v := range []Vec6{
+		{1, 0, 0, 0, 0, 0},
+		{0, 0, 1, 0, 0, 0},
+		{0, 0, 0, 1, 0, 0},
+		{0, 0, 0, 0, 0, 1},
+		{0.1, 0.2, 0.3, 0.4, 0.5, 0.6},
+	} {
+		expected := m.Mul(hat(v)).Mul(m.InvAffine())
+		out := hat(ad.MulVec(v))
+		for i := range out {
+			if diff := out[i] - expected[i]; diff < -0.0001 || 0.0001 < diff {
+				t.Fatalf("Expected:\n%v\ngot:\n%v", expected, out)
+			}
+		}
+	}
+}
+
+func TestPoseWithCovariance(t *testing.T) {
+	const eps = 0.0001
+	nearlyEqual := func(a, b Mat6) bool {
+		for i := range a {
+			if diff := a[i] - b[i]; diff < -eps || eps < diff {
+				return false
+			}
+		}
+		return true
+	}
+
+	t.Run("Mul", func(t *testing.T) {
+		// 0.1 rad^2 yaw uncertainty at the child pose.
+		var cov Mat6
+		cov[6*5+5] = 0.1
+		p0 := PoseWithCovariance{Pose: Translate(1, 0, 0)}
+		p1 := PoseWithCovariance{Pose: Translate(0, 0, 0), Covariance: cov}
+
+		// Yaw uncertainty of the child is observed as y uncertainty
+		// due to the lever arm of the parent translation.
+		var expected Mat6
+		expected[6*1+1] = 0.1
+		expected[6*5+1] = -0.1
+		expected[6*1+5] = -0.1
+		expected[6*5+5] = 0.1
+
+		p := p0.Mul(p1)
+		if !nearlyEqual(expected, p.Covariance) {
+			t.Errorf("Expected:\n%v\ngot:\n%v", expected, p.Covariance)
+		}
+		if expectedPose := Translate(1, 0, 0); p.Pose != expectedPose {
+			t.Errorf("Expected:\n%v\ngot:\n%v", expectedPose, p.Pose)
+		}
+	})
+
+	var cov Mat6
+	for i := 0; i < 6; i++ {
+		cov[6*i+i] = 0.01 * float32(i+1)
+	}
+	cov[6*5+0], cov[6*0+5] = 0.002, 0.002
+	p := PoseWithCovariance{
+		Pose:       Translate(0.5, -1.0, 2.0).Mul(Rotate(0, 0.6, 0.8, 0.7)),
+		Covariance: cov,
+	}
+
+	t.Run("Inv", func(t *testing.T) {
+		inv := p.Inv()
+		if i := inv.Pose.Mul(p.Pose); !nearlyEqualMat4(i, Translate(0, 0, 0), eps) {
+			t.Errorf("Expected identity, got:\n%v", i)
+		}
+		if p2 := inv.Inv(); !nearlyEqual(p.Covariance, p2.Covariance) {
+			t.Errorf("Expected:\n%v\ngot:\n%v", p.Covariance, p2.Covariance)
+		}
+		// Composition with the inverse of itself makes zero mean transform
+		// with twice of the uncertainty.
+		z := p.Mul(inv)
+		if !nearlyEqual(p.Covariance.Factor(2), z.Covariance) {
+			t.Errorf("Expected:\n%v\ngot:\n%v", p.Covariance.Factor(2), z.Covariance)
+		}
+	})
+
+	t.Run("Relative", func(t *testing.T) {
+		d := PoseWithCovariance{
+			Pose:       Translate(0.1, 0.2, 0).Mul(Rotate(0, 0, 1, 0.1)),
+			Covariance: cov.Factor(0.5),
+		}
+		p2 := p.Mul(d)
+		r := p.Relative(p2)
+		if !nearlyEqualMat4(d.Pose, r.Pose, eps) {
+			t.Errorf("Expected:\n%v\ngot:\n%v", d.Pose, r.Pose)
+		}
+		adInv := Adjoint(p.Pose.InvAffine())
+		expected := adInv.Mul(p.Covariance.Add(p2.Covariance)).Mul(adInv.Transpose())
+		if !nearlyEqual(expected, r.Covariance) {
+			t.Errorf("Expected:\n%v\ngot:\n%v", expected, r.Covariance)
+		}
+	})
+}
+
+func nearlyEqualMat4(a, b Mat4, eps float32) bool {
+	for i := range a {
+		if diff := a[i] - b[i]; diff < -eps || eps < diff {
+			return false
+		}
+	}
+	return true
+}
@@ -64,6 +64,16 @@ func (v Vec3) Equal(a Vec3) bool {
 	return a[0] == v[0] && a[1] == v[1] && a[2] == v[2]
 }
 
+// IsFinite returns false if any element is NaN or infinity.
+func (v Vec3) IsFinite() bool {
+	for _, a := range v {
+		if math.IsNaN(float64(a)) || math.IsInf(float64(a), 0) {
+			return false
+		}
+	}
+	return true
+}
+
 func (v Vec3) String() string {
 	return fmt.Sprintf("{%0.3f, %0.3f, %0.3f}", v[0], v[1], v[2])
 }
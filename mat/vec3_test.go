@@ -1,6 +1,7 @@
 package mat
 
 import (
+	"math"
 	"reflect"
 	"testing"
 )
@@ -54,3 +55,25 @@ func TestVec3_Equal(t *testing.T) {
 		}
 	})
 }
+
+func TestVec3_IsFinite(t *testing.T) {
+	nan := float32(math.NaN())
+	inf := float32(math.Inf(1))
+	testCases := map[string]struct {
+		v        Vec3
+		expected bool
+	}{
+		"Finite":   {v: Vec3{1, -2, 3}, expected: true},
+		"NaN":      {v: Vec3{1, nan, 3}},
+		"Inf":      {v: Vec3{1, 2, inf}},
+		"MinusInf": {v: Vec3{-inf, 2, 3}},
+	}
+	for name, tt := range testCases {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			if f := tt.v.IsFinite(); f != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, f)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package mat
+
+import (
+	"fmt"
+	"math"
+)
+
+// Vec3d is a float64 version of Vec3.
+// It can be used to handle large coordinates like UTM or ECEF without losing precision.
+type Vec3d [3]float64
+
+func NewVec3d(x, y, z float64) Vec3d {
+	return Vec3d{x, y, z}
+}
+
+// Vec3d converts Vec3 to Vec3d.
+func (v Vec3) Vec3d() Vec3d {
+	return Vec3d{float64(v[0]), float64(v[1]), float64(v[2])}
+}
+
+// Vec3 converts Vec3d to Vec3.
+func (v Vec3d) Vec3() Vec3 {
+	return Vec3{float32(v[0]), float32(v[1]), float32(v[2])}
+}
+
+func (v Vec3d) Floats() [3]float64 {
+	return v
+}
+
+func (v Vec3d) NormSq() float64 {
+	return v[0]*v[0] + v[1]*v[1] + v[2]*v[2]
+}
+
+func (v Vec3d) Norm() float64 {
+	return math.Sqrt(v.NormSq())
+}
+
+func (v Vec3d) Normalized() Vec3d {
+	return v.Mul(1.0 / v.Norm())
+}
+
+func (v Vec3d) Mul(a float64) Vec3d {
+	return Vec3d{v[0] * a, v[1] * a, v[2] * a}
+}
+
+func (v Vec3d) ElementMul(a Vec3d) Vec3d {
+	return Vec3d{v[0] * a[0], v[1] * a[1], v[2] * a[2]}
+}
+
+func (v Vec3d) Sub(a Vec3d) Vec3d {
+	return Vec3d{v[0] - a[0], v[1] - a[1], v[2] - a[2]}
+}
+
+func (v Vec3d) Add(a Vec3d) Vec3d {
+	return Vec3d{v[0] + a[0], v[1] + a[1], v[2] + a[2]}
+}
+
+func (v Vec3d) Dot(a Vec3d) float64 {
+	return v[0]*a[0] + v[1]*a[1] + v[2]*a[2]
+}
+
+func (v Vec3d) Cross(a Vec3d) Vec3d {
+	return Vec3d{
+		v[1]*a[2] - v[2]*a[1],
+		v[2]*a[0] - v[0]*a[2],
+		v[0]*a[1] - v[1]*a[0],
+	}
+}
+
+func (v Vec3d) Equal(a Vec3d) bool {
+	return a[0] == v[0] && a[1] == v[1] && a[2] == v[2]
+}
+
+func (v Vec3d) String() string {
+	return fmt.Sprintf("{%0.3f, %0.3f, %0.3f}", v[0], v[1], v[2])
+}
@@ -0,0 +1,38 @@
+package mat
+
+import (
+	"testing"
+)
+
+func TestVec3d_Cross(t *testing.T) {
+	x := Vec3d{1, 0, 0}
+	y := Vec3d{0, 1, 0}
+
+	if c := x.Cross(y); !c.Equal(Vec3d{0, 0, 1}) {
+		t.Errorf("Expected %v, got %v", Vec3d{0, 0, 1}, c)
+	}
+}
+
+func TestVec3d_Precision(t *testing.T) {
+	// UTM-like coordinates which can't be represented by float32 in centimeter precision.
+	a := Vec3d{500000.01, 4000000.02, 10.03}
+	b := Vec3d{500000.00, 4000000.00, 10.00}
+
+	d := a.Sub(b).Vec3()
+	expected := Vec3{0.01, 0.02, 0.03}
+	for i := range d {
+		if diff := d[i] - expected[i]; diff < -1e-6 || 1e-6 < diff {
+			t.Errorf("Expected %v, got %v", expected, d)
+		}
+	}
+}
+
+func TestVec3d_Conversion(t *testing.T) {
+	v := Vec3{1.5, -2.25, 3}
+	if v2 := v.Vec3d().Vec3(); !v2.Equal(v) {
+		t.Errorf("Expected %v, got %v", v, v2)
+	}
+	if n := (Vec3d{3, 4, 0}).Norm(); n != 5 {
+		t.Errorf("Expected norm: 5, got: %f", n)
+	}
+}
@@ -0,0 +1,155 @@
+package camera
+
+import (
+	"errors"
+	"math"
+
+	"github.com/seqsense/pcgol/mat"
+	"github.com/seqsense/pcgol/pc"
+)
+
+const undistortIteration = 20
+
+var ErrImageSize = errors.New("image size mismatch")
+
+// Intrinsics represents pinhole camera model with Brown-Conrady distortion.
+//
+// Camera coordinate system is x-right, y-down and z-forward.
+// Pixel coordinates (u, v) point the center of the pixel at integer values.
+type Intrinsics struct {
+	Width, Height int
+
+	Fx, Fy float32
+	Cx, Cy float32
+
+	// Radial distortion coefficients
+	K1, K2, K3 float32
+	// Tangential distortion coefficients
+	P1, P2 float32
+}
+
+func (c *Intrinsics) hasDistortion() bool {
+	return c.K1 != 0 || c.K2 != 0 || c.K3 != 0 || c.P1 != 0 || c.P2 != 0
+}
+
+// Distort applies lens distortion to the normalized image coordinates.
+func (c *Intrinsics) Distort(x, y float32) (float32, float32) {
+	r2 := x*x + y*y
+	radial := 1 + r2*(c.K1+r2*(c.K2+r2*c.K3))
+	return x*radial + 2*c.P1*x*y + c.P2*(r2+2*x*x),
+		y*radial + c.P1*(r2+2*y*y) + 2*c.P2*x*y
+}
+
+// Undistort removes lens distortion from the normalized image coordinates.
+// It's numerically solved by fixed-point iteration.
+func (c *Intrinsics) Undistort(xd, yd float32) (float32, float32) {
+	x, y := xd, yd
+	for i := 0; i < undistortIteration; i++ {
+		r2 := x*x + y*y
+		radial := 1 + r2*(c.K1+r2*(c.K2+r2*c.K3))
+		dx := 2*c.P1*x*y + c.P2*(r2+2*x*x)
+		dy := c.P1*(r2+2*y*y) + 2*c.P2*x*y
+		x = (xd - dx) / radial
+		y = (yd - dy) / radial
+	}
+	return x, y
+}
+
+// Project projects the point on the camera coordinates to the pixel coordinates.
+// ok is false if the point is behind the camera or has non-finite coordinate.
+func (c *Intrinsics) Project(p mat.Vec3) (u, v float32, ok bool) {
+	if !p.IsFinite() || p[2] <= 0 {
+		return 0, 0, false
+	}
+	x, y := p[0]/p[2], p[1]/p[2]
+	if c.hasDistortion() {
+		x, y = c.Distort(x, y)
+	}
+	return c.Fx*x + c.Cx, c.Fy*y + c.Cy, true
+}
+
+// Unproject returns the point on the camera coordinates
+// at the pixel coordinates with the depth (z value).
+func (c *Intrinsics) Unproject(u, v, depth float32) mat.Vec3 {
+	x, y := (u-c.Cx)/c.Fx, (v-c.Cy)/c.Fy
+	if c.hasDistortion() {
+		x, y = c.Undistort(x, y)
+	}
+	return mat.Vec3{x * depth, y * depth, depth}
+}
+
+// Perspective returns projection matrix like mat.Perspective
+// for the camera coordinate system.
+// Points in the view frustum are transformed into [-1, 1] cube by Mat4.Transform.
+// Distortion is not considered.
+func (c *Intrinsics) Perspective(near, far float32) mat.Mat4 {
+	w, h := float32(c.Width), float32(c.Height)
+	return mat.Mat4{
+		2 * c.Fx / w, 0, 0, 0,
+		0, 2 * c.Fy / h, 0, 0,
+		2*(c.Cx+0.5)/w - 1, 2*(c.Cy+0.5)/h - 1, (far + near) / (far - near), 1,
+		0, 0, -2 * far * near / (far - near), 0,
+	}
+}
+
+// DepthImage is a row-major depth (z value) image in meters.
+// Zero or NaN means no measurement.
+type DepthImage struct {
+	Width, Height int
+	Depth         []float32
+}
+
+// NewDepthImage allocates DepthImage filled by zero.
+func NewDepthImage(width, height int) *DepthImage {
+	return &DepthImage{
+		Width:  width,
+		Height: height,
+		Depth:  make([]float32, width*height),
+	}
+}
+
+// PointCloud converts the depth image to an organized PointCloud with x, y, z fields.
+// Pixels without measurement are converted to NaN points.
+func (c *Intrinsics) PointCloud(d *DepthImage) (*pc.PointCloud, error) {
+	if d.Width != c.Width || d.Height != c.Height || len(d.Depth) != d.Width*d.Height {
+		return nil, ErrImageSize
+	}
+	nan := float32(math.NaN())
+	points := make([]mat.Vec3, 0, d.Width*d.Height)
+	for v := 0; v < d.Height; v++ {
+		for u := 0; u < d.Width; u++ {
+			depth := d.Depth[v*d.Width+u]
+			if !(depth > 0) || math.IsInf(float64(depth), 0) {
+				points = append(points, mat.Vec3{nan, nan, nan})
+			} else {
+				points = append(points, c.Unproject(float32(u), float32(v), depth))
+			}
+		}
+	}
+	pp := pc.NewXYZPointCloud(points)
+	pp.Width, pp.Height = d.Width, d.Height
+	return pp, nil
+}
+
+// DepthImage renders the points on the camera coordinates to the depth image.
+// Nearest point is used if multiple points are projected to the same pixel.
+func (c *Intrinsics) DepthImage(ra pc.Vec3RandomAccessor) *DepthImage {
+	d := NewDepthImage(c.Width, c.Height)
+	n := ra.Len()
+	for i := 0; i < n; i++ {
+		p := ra.Vec3At(i)
+		u, v, ok := c.Project(p)
+		if !ok {
+			continue
+		}
+		ui, vi := int(math.Floor(float64(u)+0.5)), int(math.Floor(float64(v)+0.5))
+		if ui < 0 || vi < 0 || ui >= c.Width || vi >= c.Height {
+			continue
+		}
+		a := vi*c.Width + ui
+		if depth := d.Depth[a]; depth == 0 || p[2] < depth {
+			d.Depth[a] = p[2]
+		}
+	}
+	return d
+}
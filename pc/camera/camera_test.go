@@ -0,0 +1,156 @@
+package camera
+
+import (
+	"math"
+	"testing"
+
+	"github.com/seqsense/pcgol/mat"
+	"github.com/seqsense/pcgol/pc"
+)
+
+func TestIntrinsics_ProjectUnproject(t *testing.T) {
+	testCases := map[string]Intrinsics{
+		"NoDistortion": {
+			Width: 640, Height: 480,
+			Fx: 500, Fy: 510, Cx: 320.5, Cy: 240.5,
+		},
+		"Distortion": {
+			Width: 640, Height: 480,
+			Fx: 500, Fy: 510, Cx: 320.5, Cy: 240.5,
+			K1: -0.1, K2: 0.02, K3: -0.001, P1: 0.001, P2: -0.002,
+		},
+	}
+	for name, c := range testCases {
+		c := c
+		t.Run(name, func(t *testing.T) {
+			for _, p := range []mat.Vec3{
+				{0, 0, 1},
+				{0.5, -0.3, 2},
+				{-1.2, 0.8, 3.5},
+			} {
+				u, v, ok := c.Project(p)
+				if !ok {
+					t.Fatalf("Point %v in front of the camera must be projected", p)
+				}
+				p2 := c.Unproject(u, v, p[2])
+				if d := p2.Sub(p).Norm(); d > 0.0001 {
+					t.Errorf("Expected %v, got %v", p, p2)
+				}
+			}
+			if _, _, ok := c.Project(mat.Vec3{0, 0, -1}); ok {
+				t.Error("Point behind the camera must not be projected")
+			}
+		})
+	}
+}
+
+func TestIntrinsics_Perspective(t *testing.T) {
+	c := Intrinsics{
+		Width: 640, Height: 480,
+		Fx: 320, Fy: 320, Cx: 319.5, Cy: 239.5,
+	}
+	const near, far = 0.1, 10
+
+	// Equivalent to mat.Perspective looking at z+ direction
+	expected := mat.Perspective(math.Pi/2, 4.0/3.0, near, far).Mul(mat.Scale(1, 1, -1))
+	m := c.Perspective(near, far)
+	for i := range m {
+		if diff := m[i] - expected[i]; diff < -0.0001 || 0.0001 < diff {
+			t.Fatalf("Expected:\n%v\ngot:\n%v", expected, m)
+		}
+	}
+
+	// Image corners and depth range are mapped to the edge of [-1, 1] cube.
+	for _, tt := range []struct {
+		u, v, depth float32
+		expected    mat.Vec3
+	}{
+		{-0.5, -0.5, near, mat.Vec3{-1, -1, -1}},
+		{639.5, 479.5, far, mat.Vec3{1, 1, 1}},
+		{319.5, 239.5, near, mat.Vec3{0, 0, -1}},
+	} {
+		p := m.Transform(c.Unproject(tt.u, tt.v, tt.depth))
+		if d := p.Sub(tt.expected).Norm(); d > 0.0001 {
+			t.Errorf("Expected %v, got %v", tt.expected, p)
+		}
+	}
+}
+
+func TestIntrinsics_PointCloud(t *testing.T) {
+	c := Intrinsics{
+		Width: 4, Height: 3,
+		Fx: 2, Fy: 2, Cx: 1.5, Cy: 1,
+	}
+	nan := float32(math.NaN())
+	d := &DepthImage{
+		Width:  4,
+		Height: 3,
+		Depth: []float32{
+			1, 2, 0, 1,
+			1, 2, 2, 1,
+			nan, 3, 3, 4,
+		},
+	}
+	pp, err := c.PointCloud(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pp.Width != 4 || pp.Height != 3 || pp.Points != 12 {
+		t.Fatalf("Expected 4x3 organized point cloud, got %dx%d (%d points)", pp.Width, pp.Height, pp.Points)
+	}
+	it, err := pp.Vec3Iterator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := it.Vec3At(1); !p.Equal(mat.Vec3{-0.5, -1, 2}) {
+		t.Errorf("Expected %v, got %v", mat.Vec3{-0.5, -1, 2}, p)
+	}
+	for _, i := range []int{2, 8} {
+		if p := it.Vec3At(i); !math.IsNaN(float64(p[0])) {
+			t.Errorf("Point %d without depth must be NaN, got %v", i, p)
+		}
+	}
+
+	t.Run("DepthImage", func(t *testing.T) {
+		// Invalid pixels are converted back from NaN points.
+		points := make(pc.Vec3Slice, 0, it.Len())
+		for i := 0; i < it.Len(); i++ {
+			points = append(points, it.Vec3At(i))
+		}
+		// Point behind the other point must be hidden.
+		points = append(points, it.Vec3At(1).Mul(2.5))
+
+		d2 := c.DepthImage(points)
+		for i := range d.Depth {
+			e := d.Depth[i]
+			if math.IsNaN(float64(e)) {
+				e = 0
+			}
+			if diff := d2.Depth[i] - e; diff < -0.0001 || 0.0001 < diff {
+				t.Errorf("Expected depth:\n%v\ngot:\n%v", d.Depth, d2.Depth)
+				break
+			}
+		}
+	})
+
+	t.Run("NonFinitePoints", func(t *testing.T) {
+		inf := float32(math.Inf(1))
+		d2 := c.DepthImage(pc.Vec3Slice{
+			{nan, nan, nan},
+			{0, 0, nan},
+			{0, 0, inf},
+			{inf, 0, 1},
+		})
+		for i, depth := range d2.Depth {
+			if depth != 0 {
+				t.Errorf("Expected no depth at %d, got %f", i, depth)
+			}
+		}
+	})
+
+	t.Run("SizeMismatch", func(t *testing.T) {
+		if _, err := c.PointCloud(NewDepthImage(3, 3)); err != ErrImageSize {
+			t.Errorf("Expected error: %v, got: %v", ErrImageSize, err)
+		}
+	})
+}
@@ -0,0 +1,65 @@
+// Package cropbox implements a filter to keep points inside the box.
+package cropbox
+
+import (
+	"github.com/seqsense/pcgol/mat"
+	"github.com/seqsense/pcgol/pc"
+	"github.com/seqsense/pcgol/pc/filter"
+)
+
+type cropBox struct {
+	Options
+
+	min, max mat.Vec3
+}
+
+// New creates crop-box filter keeping the points in the axis aligned box [min, max].
+// The box can be moved by WithTransform option.
+func New(min, max mat.Vec3, opts ...Option) filter.IndiceFilter {
+	f := &cropBox{
+		min: min,
+		max: max,
+	}
+	for _, o := range opts {
+		o(&f.Options)
+	}
+	return f
+}
+
+func (f *cropBox) Filter(pp *pc.PointCloud) (*pc.PointCloud, error) {
+	return filter.FilterByIndice(f, pp)
+}
+
+func (f *cropBox) FilterIndice(pp *pc.PointCloud) ([]int, []int, error) {
+	it, err := pp.Vec3Iterator()
+	if err != nil {
+		return nil, nil, err
+	}
+	var inv *mat.Mat4
+	if f.Transform != nil {
+		m := f.Transform.InvAffine()
+		inv = &m
+	}
+	var kept, removed []int
+	for i := 0; i < it.Len(); i++ {
+		p := it.Vec3At(i)
+		if inv != nil {
+			p = inv.TransformAffine(p)
+		}
+		if p.IsFinite() && f.inside(p) != f.Negative {
+			kept = append(kept, i)
+		} else if f.ExtractRemoved {
+			removed = append(removed, i)
+		}
+	}
+	return kept, removed, nil
+}
+
+func (f *cropBox) inside(p mat.Vec3) bool {
+	for i := 0; i < 3; i++ {
+		if !(f.min[i] <= p[i] && p[i] <= f.max[i]) {
+			return false
+		}
+	}
+	return true
+}
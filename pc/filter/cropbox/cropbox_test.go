@@ -0,0 +1,66 @@
+package cropbox
+
+import (
+	"math"
+	"testing"
+
+	"github.com/seqsense/pcgol/mat"
+	"github.com/seqsense/pcgol/pc"
+	"github.com/seqsense/pcgol/pc/internal/float"
+	"github.com/seqsense/pcgol/pc/internal/pctest"
+)
+
+func TestCropBox(t *testing.T) {
+	pp := &pc.PointCloud{
+		PointCloudHeader: pc.PointCloudHeader{
+			Fields: []string{"x", "y", "z"},
+			Size:   []int{4, 4, 4},
+			Count:  []int{1, 1, 1},
+			Width:  6,
+			Height: 1,
+		},
+		Points: 6,
+		Data: float.Float32SliceAsByteSlice([]float32{
+			0.5, 0.5, 0.5,
+			1.5, 0.5, 0.5,
+			-0.5, 0.5, 0.5,
+			-0.5, -0.5, 0.5,
+			10.5, 0.5, 0.5,
+			float32(math.NaN()), 0.5, 0.5,
+		}),
+	}
+
+	testCases := map[string]struct {
+		opts            []Option
+		expectedKept    []int
+		expectedRemoved []int
+	}{
+		"Default": {
+			expectedKept: []int{0},
+		},
+		"Negative": {
+			opts:            []Option{WithNegative(), WithExtractRemoved()},
+			expectedKept:    []int{1, 2, 3, 4},
+			expectedRemoved: []int{0, 5},
+		},
+		"Translated": {
+			opts:         []Option{WithTransform(mat.Translate(10, 0, 0))},
+			expectedKept: []int{4},
+		},
+		"Rotated": {
+			opts: []Option{
+				WithTransform(mat.Rotate(0, 0, 1, math.Pi)),
+				WithExtractRemoved(),
+			},
+			expectedKept:    []int{3},
+			expectedRemoved: []int{0, 1, 2, 4, 5},
+		},
+	}
+	for name, tt := range testCases {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			f := New(mat.Vec3{0, 0, 0}, mat.Vec3{1, 1, 1}, tt.opts...)
+			pctest.CheckIndiceFilter(t, f, pp, tt.expectedKept, tt.expectedRemoved)
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package cropbox
+
+import (
+	"github.com/seqsense/pcgol/mat"
+)
+
+type Options struct {
+	Negative       bool
+	ExtractRemoved bool
+	Transform      *mat.Mat4
+}
+
+type Option func(*Options)
+
+// WithNegative inverts the condition to keep the points out of the box.
+func WithNegative() Option {
+	return Option(func(o *Options) {
+		o.Negative = true
+	})
+}
+
+// WithExtractRemoved makes FilterIndice also return the indice of the points cropped out.
+// With WithNegative, they are the points inside the box.
+func WithExtractRemoved() Option {
+	return Option(func(o *Options) {
+		o.ExtractRemoved = true
+	})
+}
+
+// WithTransform sets the pose of the box.
+// m must be an affine transform.
+func WithTransform(m mat.Mat4) Option {
+	return Option(func(o *Options) {
+		o.Transform = &m
+	})
+}
@@ -7,3 +7,37 @@ import (
 type Filter interface {
 	Filter(*pc.PointCloud) (*pc.PointCloud, error)
 }
+
+// IndiceFilter is a Filter which selects the points without modifying them.
+type IndiceFilter interface {
+	Filter
+	// FilterIndice returns indices of the kept points and the removed points.
+	// removed is nil unless the filter is configured to return it.
+	FilterIndice(*pc.PointCloud) (kept, removed []int, err error)
+}
+
+// Extract copies the points at the indices to the new unorganized PointCloud.
+func Extract(pp *pc.PointCloud, indice []int) *pc.PointCloud {
+	n := len(indice)
+	newPc := &pc.PointCloud{
+		PointCloudHeader: pp.Clone(),
+		Points:           n,
+		Data:             make([]byte, pp.Stride()*n),
+	}
+	newPc.Width = n
+	newPc.Height = 1
+	for i, id := range indice {
+		pc.Copy(newPc, i, pp, id, 1)
+	}
+	return newPc
+}
+
+// FilterByIndice extracts the points kept by FilterIndice of f.
+// It can be used to implement Filter method of IndiceFilter.
+func FilterByIndice(f IndiceFilter, pp *pc.PointCloud) (*pc.PointCloud, error) {
+	kept, _, err := f.FilterIndice(pp)
+	if err != nil {
+		return nil, err
+	}
+	return Extract(pp, kept), nil
+}
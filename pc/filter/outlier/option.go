@@ -0,0 +1,63 @@
+package outlier
+
+import (
+	"math"
+
+	"github.com/seqsense/pcgol/pc"
+	"github.com/seqsense/pcgol/pc/storage"
+	"github.com/seqsense/pcgol/pc/storage/kdtree"
+)
+
+type Options struct {
+	Search         storage.Search
+	Workers        int
+	MaxRange       float32
+	ExtractRemoved bool
+}
+
+var defaultOptions = Options{
+	Workers:  1,
+	MaxRange: float32(math.Inf(1)),
+}
+
+type Option func(*Options)
+
+// WithSearch sets the search structure of the input point cloud to be reused.
+// IDs of the search must correspond to the point indices of the input point cloud.
+// KDTree is built on each Filter call if not specified.
+func WithSearch(s storage.Search) Option {
+	return Option(func(o *Options) {
+		o.Search = s
+	})
+}
+
+// WithWorkers sets the number of goroutines to search the neighbors.
+// Search must be safe for concurrent use if n is larger than 1.
+func WithWorkers(n int) Option {
+	return Option(func(o *Options) {
+		o.Workers = n
+	})
+}
+
+// WithMaxRange sets the maximum range of the k-nearest neighbor search of the statistical filter.
+// Search which is inefficient on large range query like voxel grid search requires it.
+func WithMaxRange(r float32) Option {
+	return Option(func(o *Options) {
+		o.MaxRange = r
+	})
+}
+
+// WithExtractRemoved makes FilterIndice also return the indice of the points detected as outliers.
+func WithExtractRemoved() Option {
+	return Option(func(o *Options) {
+		o.ExtractRemoved = true
+	})
+}
+
+func (o *Options) batchSearch(ra pc.Vec3RandomAccessor) storage.BatchSearch {
+	s := o.Search
+	if s == nil {
+		s = kdtree.New(ra)
+	}
+	return storage.BatchSearch{Search: s, Workers: o.Workers}
+}
@@ -0,0 +1,15 @@
+// Package outlier implements filters to remove isolated noise points.
+package outlier
+
+func split(n int, fn func(i int) bool, extractRemoved bool) ([]int, []int) {
+	kept := make([]int, 0, n)
+	var removed []int
+	for i := 0; i < n; i++ {
+		if fn(i) {
+			kept = append(kept, i)
+		} else if extractRemoved {
+			removed = append(removed, i)
+		}
+	}
+	return kept, removed
+}
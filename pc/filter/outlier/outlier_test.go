@@ -0,0 +1,26 @@
+package outlier
+
+import (
+	"github.com/seqsense/pcgol/mat"
+)
+
+// gridWithOutliers returns 10x10 grid points with 0.1 interval followed by outliers.
+func gridWithOutliers() ([]mat.Vec3, []int, []int) {
+	var points []mat.Vec3
+	var inliers, outliers []int
+	for x := 0; x < 10; x++ {
+		for y := 0; y < 10; y++ {
+			inliers = append(inliers, len(points))
+			points = append(points, mat.Vec3{float32(x) * 0.1, float32(y) * 0.1, 0})
+		}
+	}
+	for _, p := range []mat.Vec3{
+		{0.45, 0.45, 2},
+		{-3, 0, 0},
+		{5, 5, 5},
+	} {
+		outliers = append(outliers, len(points))
+		points = append(points, p)
+	}
+	return points, inliers, outliers
+}
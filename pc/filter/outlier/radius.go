@@ -0,0 +1,53 @@
+package outlier
+
+import (
+	"github.com/seqsense/pcgol/pc"
+	"github.com/seqsense/pcgol/pc/filter"
+)
+
+type radius struct {
+	Options
+
+	r            float32
+	minNeighbors int
+}
+
+// NewRadius creates radius outlier removal filter.
+// Points with less than minNeighbors neighbors within radius r are removed.
+// The point itself is not counted as a neighbor.
+// Points with non-finite coordinate are always removed.
+func NewRadius(r float32, minNeighbors int, opts ...Option) filter.IndiceFilter {
+	f := &radius{
+		Options:      defaultOptions,
+		r:            r,
+		minNeighbors: minNeighbors,
+	}
+	for _, o := range opts {
+		o(&f.Options)
+	}
+	return f
+}
+
+func (f *radius) Filter(pp *pc.PointCloud) (*pc.PointCloud, error) {
+	return filter.FilterByIndice(f, pp)
+}
+
+func (f *radius) FilterIndice(pp *pc.PointCloud) ([]int, []int, error) {
+	it, err := pp.Vec3Iterator()
+	if err != nil {
+		return nil, nil, err
+	}
+	cnts := f.batchSearch(it).RangeCountAll(it, f.r)
+	kept, removed := split(it.Len(), func(i int) bool {
+		if !it.Vec3At(i).IsFinite() {
+			return false
+		}
+		// The point itself is not found if r is zero.
+		n := cnts[i] - 1
+		if n < 0 {
+			n = 0
+		}
+		return n >= f.minNeighbors
+	}, f.ExtractRemoved)
+	return kept, removed, nil
+}
@@ -0,0 +1,118 @@
+package outlier
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/seqsense/pcgol/mat"
+	"github.com/seqsense/pcgol/pc"
+	"github.com/seqsense/pcgol/pc/internal/pctest"
+	"github.com/seqsense/pcgol/pc/storage/voxelgrid"
+)
+
+func TestRadius(t *testing.T) {
+	points, inliers, outliers := gridWithOutliers()
+	pp := pc.NewXYZPointCloud(points)
+	it, err := pp.Vec3Iterator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	vg := voxelgrid.NewSparse(0.2, points[0])
+	for i := 0; i < it.Len(); i++ {
+		vg.Add(it.Vec3At(i), i)
+	}
+
+	testCases := map[string]struct {
+		minNeighbors    int
+		opts            []Option
+		expectedKept    []int
+		expectedRemoved []int
+	}{
+		"Default": {
+			minNeighbors: 2,
+			expectedKept: inliers,
+		},
+		"ExtractRemoved": {
+			minNeighbors:    2,
+			opts:            []Option{WithExtractRemoved()},
+			expectedKept:    inliers,
+			expectedRemoved: outliers,
+		},
+		"WithSearch": {
+			minNeighbors:    2,
+			opts:            []Option{WithSearch(vg.Search(it)), WithExtractRemoved()},
+			expectedKept:    inliers,
+			expectedRemoved: outliers,
+		},
+		"WithWorkers": {
+			minNeighbors:    2,
+			opts:            []Option{WithWorkers(4), WithExtractRemoved()},
+			expectedKept:    inliers,
+			expectedRemoved: outliers,
+		},
+		"TooManyNeighbors": {
+			// Grid points have at most 4 neighbors within the radius.
+			minNeighbors: 5,
+		},
+	}
+	for name, tt := range testCases {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			f := NewRadius(0.12, tt.minNeighbors, tt.opts...)
+			pctest.CheckIndiceFilter(t, f, pp, tt.expectedKept, tt.expectedRemoved)
+		})
+	}
+}
+
+func TestRadius_selfNotFound(t *testing.T) {
+	nan := float32(math.NaN())
+	pp := pc.NewXYZPointCloud([]mat.Vec3{{0, 0, 0}, {nan, 0, 0}, {1, 0, 0}})
+
+	testCases := map[string]struct {
+		r               float32
+		minNeighbors    int
+		expectedKept    []int
+		expectedRemoved []int
+	}{
+		"ZeroRadius": {
+			r:               0,
+			minNeighbors:    0,
+			expectedKept:    []int{0, 2},
+			expectedRemoved: []int{1},
+		},
+		"ZeroRadiusWithNeighbors": {
+			r:               0,
+			minNeighbors:    1,
+			expectedRemoved: []int{0, 1, 2},
+		},
+		"NaN": {
+			r:               2,
+			minNeighbors:    1,
+			expectedKept:    []int{0, 2},
+			expectedRemoved: []int{1},
+		},
+	}
+	for name, tt := range testCases {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			f := NewRadius(tt.r, tt.minNeighbors, WithExtractRemoved())
+			pctest.CheckIndiceFilter(t, f, pp, tt.expectedKept, tt.expectedRemoved)
+		})
+	}
+}
+
+func TestRadius_workers(t *testing.T) {
+	pp := pc.NewXYZPointCloud(pctest.RandomCloud(5000, 0, 10))
+	expected, _, err := NewRadius(0.5, 3).FilterIndice(pp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kept, _, err := NewRadius(0.5, 3, WithWorkers(4)).FilterIndice(pp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expected, kept) {
+		t.Error("Result with workers differs")
+	}
+}
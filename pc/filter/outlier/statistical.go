@@ -0,0 +1,85 @@
+package outlier
+
+import (
+	"math"
+
+	"github.com/seqsense/pcgol/pc"
+	"github.com/seqsense/pcgol/pc/filter"
+)
+
+type statistical struct {
+	Options
+
+	k     int
+	alpha float32
+}
+
+// NewStatistical creates statistical outlier removal filter.
+// Mean distance to the k nearest neighbors is calculated for each point
+// and the points with the mean distance larger than mean + alpha * stddev
+// of the whole point cloud are removed.
+// Points without any neighbor are also removed.
+func NewStatistical(k int, alpha float32, opts ...Option) filter.IndiceFilter {
+	f := &statistical{
+		Options: defaultOptions,
+		k:       k,
+		alpha:   alpha,
+	}
+	for _, o := range opts {
+		o(&f.Options)
+	}
+	return f
+}
+
+func (f *statistical) Filter(pp *pc.PointCloud) (*pc.PointCloud, error) {
+	return filter.FilterByIndice(f, pp)
+}
+
+func (f *statistical) FilterIndice(pp *pc.PointCloud) ([]int, []int, error) {
+	it, err := pp.Vec3Iterator()
+	if err != nil {
+		return nil, nil, err
+	}
+	n := it.Len()
+	// Search k+1 points since the result includes the query point itself.
+	knns := f.batchSearch(it).KNearestAll(it, f.k+1, f.MaxRange)
+
+	dists := make([]float64, n)
+	var sum, sumSq float64
+	var nValid int
+	for i, nns := range knns {
+		var d float64
+		var cnt int
+		for _, nn := range nns {
+			if nn.ID == i || cnt == f.k {
+				continue
+			}
+			d += math.Sqrt(float64(nn.DistSq))
+			cnt++
+		}
+		if cnt == 0 {
+			dists[i] = math.Inf(1)
+			continue
+		}
+		d /= float64(cnt)
+		dists[i] = d
+		sum += d
+		sumSq += d * d
+		nValid++
+	}
+	if nValid == 0 {
+		kept, removed := split(n, func(int) bool { return false }, f.ExtractRemoved)
+		return kept, removed, nil
+	}
+	mean := sum / float64(nValid)
+	variance := sumSq/float64(nValid) - mean*mean
+	if variance < 0 {
+		variance = 0
+	}
+	th := mean + float64(f.alpha)*math.Sqrt(variance)
+
+	kept, removed := split(n, func(i int) bool {
+		return dists[i] <= th
+	}, f.ExtractRemoved)
+	return kept, removed, nil
+}
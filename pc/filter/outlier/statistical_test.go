@@ -0,0 +1,67 @@
+package outlier
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/seqsense/pcgol/mat"
+	"github.com/seqsense/pcgol/pc"
+	"github.com/seqsense/pcgol/pc/internal/pctest"
+	"github.com/seqsense/pcgol/pc/storage/bruteforce"
+)
+
+func TestStatistical(t *testing.T) {
+	points, inliers, outliers := gridWithOutliers()
+	pp := pc.NewXYZPointCloud(points)
+	it, err := pp.Vec3Iterator()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := map[string]struct {
+		opts            []Option
+		expectedRemoved []int
+	}{
+		"Default": {},
+		"ExtractRemoved": {
+			opts:            []Option{WithExtractRemoved()},
+			expectedRemoved: outliers,
+		},
+		"WithSearch": {
+			opts:            []Option{WithSearch(bruteforce.New(it)), WithExtractRemoved()},
+			expectedRemoved: outliers,
+		},
+		"WithWorkers": {
+			opts:            []Option{WithWorkers(4), WithExtractRemoved()},
+			expectedRemoved: outliers,
+		},
+	}
+	for name, tt := range testCases {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			f := NewStatistical(4, 1, tt.opts...)
+			pctest.CheckIndiceFilter(t, f, pp, inliers, tt.expectedRemoved)
+		})
+	}
+
+	t.Run("Single", func(t *testing.T) {
+		// Point without neighbor must be removed.
+		f := NewStatistical(4, 1, WithExtractRemoved())
+		pctest.CheckIndiceFilter(t, f, pc.NewXYZPointCloud([]mat.Vec3{{1, 2, 3}}), nil, []int{0})
+	})
+}
+
+func TestStatistical_workers(t *testing.T) {
+	pp := pc.NewXYZPointCloud(pctest.RandomCloud(5000, 0, 10))
+	expected, _, err := NewStatistical(8, 0.5).FilterIndice(pp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kept, _, err := NewStatistical(8, 0.5, WithWorkers(4)).FilterIndice(pp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expected, kept) {
+		t.Error("Result with workers differs")
+	}
+}
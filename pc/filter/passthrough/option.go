@@ -0,0 +1,23 @@
+package passthrough
+
+type Options struct {
+	Negative       bool
+	ExtractRemoved bool
+}
+
+type Option func(*Options)
+
+// WithNegative inverts the condition to keep the points out of the range.
+func WithNegative() Option {
+	return Option(func(o *Options) {
+		o.Negative = true
+	})
+}
+
+// WithExtractRemoved makes FilterIndice also return the indice of the points
+// whose field value doesn't pass the range check, including NaN values.
+func WithExtractRemoved() Option {
+	return Option(func(o *Options) {
+		o.ExtractRemoved = true
+	})
+}
@@ -0,0 +1,52 @@
+// Package passthrough implements a filter to keep points whose field value is in the range.
+package passthrough
+
+import (
+	"math"
+
+	"github.com/seqsense/pcgol/pc"
+	"github.com/seqsense/pcgol/pc/filter"
+)
+
+type passThrough struct {
+	Options
+
+	field    string
+	min, max float32
+}
+
+// New creates pass-through filter keeping the points with the float field value in [min, max].
+// Points with NaN value are always removed.
+func New(field string, min, max float32, opts ...Option) filter.IndiceFilter {
+	f := &passThrough{
+		field: field,
+		min:   min,
+		max:   max,
+	}
+	for _, o := range opts {
+		o(&f.Options)
+	}
+	return f
+}
+
+func (f *passThrough) Filter(pp *pc.PointCloud) (*pc.PointCloud, error) {
+	return filter.FilterByIndice(f, pp)
+}
+
+func (f *passThrough) FilterIndice(pp *pc.PointCloud) ([]int, []int, error) {
+	it, err := pp.Float32Iterator(f.field)
+	if err != nil {
+		return nil, nil, err
+	}
+	var kept, removed []int
+	for i := 0; i < it.Len(); i++ {
+		v := it.Float32At(i)
+		in := f.min <= v && v <= f.max
+		if !math.IsNaN(float64(v)) && in != f.Negative {
+			kept = append(kept, i)
+		} else if f.ExtractRemoved {
+			removed = append(removed, i)
+		}
+	}
+	return kept, removed, nil
+}
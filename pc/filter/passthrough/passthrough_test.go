@@ -0,0 +1,72 @@
+package passthrough
+
+import (
+	"math"
+	"testing"
+
+	"github.com/seqsense/pcgol/pc"
+	"github.com/seqsense/pcgol/pc/internal/float"
+	"github.com/seqsense/pcgol/pc/internal/pctest"
+)
+
+func TestPassThrough(t *testing.T) {
+	nan := float32(math.NaN())
+	pp := &pc.PointCloud{
+		PointCloudHeader: pc.PointCloudHeader{
+			Fields: []string{"x", "y", "z", "intensity"},
+			Size:   []int{4, 4, 4, 4},
+			Count:  []int{1, 1, 1, 1},
+			Width:  5,
+			Height: 1,
+		},
+		Points: 5,
+		Data: float.Float32SliceAsByteSlice([]float32{
+			0, 0, -1.0, 10,
+			1, 0, 0.5, 20,
+			2, 0, 1.0, 30,
+			3, 0, 1.5, 40,
+			4, 0, nan, 50,
+		}),
+	}
+
+	testCases := map[string]struct {
+		field           string
+		opts            []Option
+		expectedKept    []int
+		expectedRemoved []int
+	}{
+		"Z": {
+			field:        "z",
+			expectedKept: []int{1, 2},
+		},
+		"ZNegative": {
+			field:        "z",
+			opts:         []Option{WithNegative()},
+			expectedKept: []int{0, 3},
+		},
+		"ZExtractRemoved": {
+			field:           "z",
+			opts:            []Option{WithExtractRemoved()},
+			expectedKept:    []int{1, 2},
+			expectedRemoved: []int{0, 3, 4},
+		},
+		"Intensity": {
+			field:        "intensity",
+			opts:         []Option{WithNegative(), WithExtractRemoved()},
+			expectedKept: []int{0, 1, 2, 3, 4},
+		},
+	}
+	for name, tt := range testCases {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			f := New(tt.field, 0, 1, tt.opts...)
+			pctest.CheckIndiceFilter(t, f, pp, tt.expectedKept, tt.expectedRemoved)
+		})
+	}
+
+	t.Run("InvalidField", func(t *testing.T) {
+		if _, err := New("rgb", 0, 1).Filter(pp); err == nil {
+			t.Error("Expected error for the invalid field")
+		}
+	})
+}
@@ -0,0 +1,104 @@
+package sampling
+
+import (
+	"math"
+
+	"github.com/seqsense/pcgol/pc"
+	"github.com/seqsense/pcgol/pc/filter"
+)
+
+type farthestPoint struct {
+	Options
+
+	n int
+}
+
+// NewFarthestPoint creates farthest point sampling filter selecting n points.
+// Sampling starts from the first finite point and the next point is iteratively selected
+// to be the farthest from the already selected points.
+// Kept indices are in the order of the selection
+// so that any prefix of them is also a farthest point sampling.
+// Non-finite points and the duplicates of the selected points are never selected,
+// so less than n points are kept if the point cloud doesn't have n distinct finite points.
+func NewFarthestPoint(n int, opts ...Option) filter.IndiceFilter {
+	f := &farthestPoint{n: n}
+	for _, o := range opts {
+		o(&f.Options)
+	}
+	return f
+}
+
+func (f *farthestPoint) Filter(pp *pc.PointCloud) (*pc.PointCloud, error) {
+	return filter.FilterByIndice(f, pp)
+}
+
+func (f *farthestPoint) FilterIndice(pp *pc.PointCloud) ([]int, []int, error) {
+	it, err := pp.Vec3Iterator()
+	if err != nil {
+		return nil, nil, err
+	}
+	kept := f.sample(it)
+	if f.ExtractRemoved {
+		return kept, removedIndice(it.Len(), kept), nil
+	}
+	return kept, nil, nil
+}
+
+func (f *farthestPoint) sample(ra pc.Vec3RandomAccessor) []int {
+	if f.n <= 0 {
+		return []int{}
+	}
+	n := ra.Len()
+	points := make(pc.Vec3Slice, n)
+	// Non-finite points are marked as selected in advance to be skipped.
+	selected := make([]bool, n)
+	first := -1
+	for i := range points {
+		points[i] = ra.Vec3At(i)
+		if !points[i].IsFinite() {
+			selected[i] = true
+		} else if first < 0 {
+			first = i
+		}
+	}
+	if first < 0 {
+		return []int{}
+	}
+
+	nKept := f.n
+	if nKept > n {
+		nKept = n
+	}
+	kept := make([]int, 0, nKept)
+	// Squared distance to the nearest selected point.
+	distSq := make([]float32, n)
+	for i := range distSq {
+		distSq[i] = float32(math.Inf(1))
+	}
+	last := first
+	for {
+		selected[last] = true
+		kept = append(kept, last)
+		if len(kept) >= f.n {
+			return kept
+		}
+		pl := points[last]
+		var maxDistSq float32
+		last = -1
+		for i, p := range points {
+			if selected[i] {
+				continue
+			}
+			if d := p.Sub(pl).NormSq(); d < distSq[i] {
+				distSq[i] = d
+			}
+			if distSq[i] > maxDistSq {
+				maxDistSq, last = distSq[i], i
+			}
+		}
+		if last < 0 {
+			// Remaining points are duplicates of the selected points.
+			return kept
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package sampling
+
+import (
+	"math"
+	"testing"
+
+	"github.com/seqsense/pcgol/mat"
+	"github.com/seqsense/pcgol/pc"
+	"github.com/seqsense/pcgol/pc/internal/pctest"
+)
+
+func TestFarthestPoint(t *testing.T) {
+	var points []mat.Vec3
+	for i := 0; i < 10; i++ {
+		points = append(points, mat.Vec3{float32(i), 0, 0})
+	}
+	pp := pc.NewXYZPointCloud(points)
+
+	testCases := map[string]struct {
+		n               int
+		opts            []Option
+		expectedKept    []int
+		expectedRemoved []int
+	}{
+		"Three": {
+			n:            3,
+			expectedKept: []int{0, 9, 4},
+		},
+		"ExtractRemoved": {
+			n:               5,
+			opts:            []Option{WithExtractRemoved()},
+			expectedKept:    []int{0, 9, 4, 2, 6},
+			expectedRemoved: []int{1, 3, 5, 7, 8},
+		},
+		"All": {
+			n:               20,
+			opts:            []Option{WithExtractRemoved()},
+			expectedKept:    []int{0, 9, 4, 2, 6, 1, 3, 5, 7, 8},
+			expectedRemoved: []int{},
+		},
+		"Zero": {
+			n:               0,
+			opts:            []Option{WithExtractRemoved()},
+			expectedKept:    []int{},
+			expectedRemoved: []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		},
+	}
+	for name, tt := range testCases {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			f := NewFarthestPoint(tt.n, tt.opts...)
+			pctest.CheckIndiceFilter(t, f, pp, tt.expectedKept, tt.expectedRemoved)
+		})
+	}
+}
+
+func TestFarthestPoint_invalidPoints(t *testing.T) {
+	nan := float32(math.NaN())
+
+	testCases := map[string]struct {
+		points          []mat.Vec3
+		n               int
+		expectedKept    []int
+		expectedRemoved []int
+	}{
+		"NaNFirst": {
+			points:          []mat.Vec3{{nan, 0, 0}, {0, 0, 0}, {1, 0, 0}, {2, 0, 0}},
+			n:               2,
+			expectedKept:    []int{1, 3},
+			expectedRemoved: []int{0, 2},
+		},
+		"AllNaN": {
+			points:          []mat.Vec3{{nan, 0, 0}, {0, nan, 0}},
+			n:               2,
+			expectedKept:    []int{},
+			expectedRemoved: []int{0, 1},
+		},
+		"Duplicates": {
+			points:          []mat.Vec3{{0, 0, 0}, {0, 0, 0}, {1, 0, 0}, {1, 0, 0}},
+			n:               3,
+			expectedKept:    []int{0, 2},
+			expectedRemoved: []int{1, 3},
+		},
+		"MoreThanDistinct": {
+			points:          []mat.Vec3{{1, 0, 0}, {nan, 0, 0}, {1, 0, 0}, {0, 2, 0}, {1, 0, 0}},
+			n:               5,
+			expectedKept:    []int{0, 3},
+			expectedRemoved: []int{1, 2, 4},
+		},
+	}
+	for name, tt := range testCases {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			pp := pc.NewXYZPointCloud(tt.points)
+			f := NewFarthestPoint(tt.n, WithExtractRemoved())
+			pctest.CheckIndiceFilter(t, f, pp, tt.expectedKept, tt.expectedRemoved)
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package sampling
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/seqsense/pcgol/pc"
+	"github.com/seqsense/pcgol/pc/filter"
+)
+
+// ErrInvalidBins is returned by normal space sampling if the number of bins is not positive.
+var ErrInvalidBins = errors.New("number of bins must be positive")
+
+type normalSpace struct {
+	Options
+
+	n    int
+	bins int
+}
+
+// NewNormalSpace creates normal space sampling filter selecting n points.
+// Points are binned by normal_x, normal_y and normal_z fields
+// into bins^3 bins over [-1, 1] and sampled evenly from the bins.
+// Points in each bin are randomly selected.
+// Points with NaN normal are never selected.
+// No points are selected if n is not positive.
+// Kept indices are in ascending order.
+func NewNormalSpace(n, bins int, opts ...Option) filter.IndiceFilter {
+	f := &normalSpace{n: n, bins: bins}
+	for _, o := range opts {
+		o(&f.Options)
+	}
+	return f
+}
+
+func (f *normalSpace) Filter(pp *pc.PointCloud) (*pc.PointCloud, error) {
+	return filter.FilterByIndice(f, pp)
+}
+
+func (f *normalSpace) FilterIndice(pp *pc.PointCloud) ([]int, []int, error) {
+	if f.bins <= 0 {
+		return nil, nil, ErrInvalidBins
+	}
+	its, err := pp.Float32Iterators("normal_x", "normal_y", "normal_z")
+	if err != nil {
+		return nil, nil, err
+	}
+	n := its[0].Len()
+
+	binID := func(v float32) int {
+		b := int(math.Floor(float64(v+1) / 2 * float64(f.bins)))
+		switch {
+		case b < 0:
+			return 0
+		case b >= f.bins:
+			return f.bins - 1
+		}
+		return b
+	}
+	bins := make(map[int][]int)
+	for i := 0; i < n; i++ {
+		x, y, z := its[0].Float32At(i), its[1].Float32At(i), its[2].Float32At(i)
+		if math.IsNaN(float64(x)) || math.IsNaN(float64(y)) || math.IsNaN(float64(z)) {
+			continue
+		}
+		id := binID(x) + f.bins*(binID(y)+f.bins*binID(z))
+		bins[id] = append(bins[id], i)
+	}
+
+	// Sort bin IDs to make the output deterministic for the seed.
+	ids := make([]int, 0, len(bins))
+	for id := range bins {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	rnd := rand.New(rand.NewSource(f.Seed))
+	queues := make([][]int, len(ids))
+	for i, id := range ids {
+		q := bins[id]
+		rnd.Shuffle(len(q), func(i, j int) {
+			q[i], q[j] = q[j], q[i]
+		})
+		queues[i] = q
+	}
+	// Shuffle bins not to prioritize specific bins in the last round.
+	rnd.Shuffle(len(queues), func(i, j int) {
+		queues[i], queues[j] = queues[j], queues[i]
+	})
+
+	nKept := f.n
+	if nKept < 0 {
+		nKept = 0
+	}
+	kept := make([]int, 0, nKept)
+	for len(kept) < f.n && len(queues) > 0 {
+		// Take one point from each bin in turn.
+		remaining := queues[:0]
+		for _, q := range queues {
+			if len(kept) == f.n {
+				break
+			}
+			kept = append(kept, q[0])
+			if len(q) > 1 {
+				remaining = append(remaining, q[1:])
+			}
+		}
+		queues = remaining
+	}
+	sort.Ints(kept)
+
+	if f.ExtractRemoved {
+		return kept, removedIndice(n, kept), nil
+	}
+	return kept, nil, nil
+}
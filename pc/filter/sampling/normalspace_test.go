@@ -0,0 +1,154 @@
+package sampling
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/seqsense/pcgol/mat"
+	"github.com/seqsense/pcgol/pc"
+	"github.com/seqsense/pcgol/pc/internal/float"
+)
+
+func TestNormalSpace(t *testing.T) {
+	// 100 points on the floor, 5 points on the wall facing x
+	// and 5 points on the wall facing -y.
+	nan := float32(math.NaN())
+	var data []float32
+	for i := 0; i < 100; i++ {
+		data = append(data, float32(i%10), float32(i/10), 0, 0, 0, 1)
+	}
+	for i := 0; i < 5; i++ {
+		data = append(data, 0, 0, float32(i), 1, 0, 0)
+	}
+	for i := 0; i < 5; i++ {
+		data = append(data, 0, 10, float32(i), 0, -1, 0)
+	}
+	// Point without valid normal
+	data = append(data, 5, 5, 5, nan, nan, nan)
+	pp := &pc.PointCloud{
+		PointCloudHeader: pc.PointCloudHeader{
+			Fields: []string{"x", "y", "z", "normal_x", "normal_y", "normal_z"},
+			Size:   []int{4, 4, 4, 4, 4, 4},
+			Count:  []int{1, 1, 1, 1, 1, 1},
+			Width:  111,
+			Height: 1,
+		},
+		Points: 111,
+		Data:   float.Float32SliceAsByteSlice(data),
+	}
+	category := func(i int) int {
+		switch {
+		case i < 100:
+			return 0
+		case i < 105:
+			return 1
+		case i < 110:
+			return 2
+		}
+		return 3
+	}
+
+	testCases := map[string]struct {
+		n        int
+		expected [4]int
+	}{
+		"One": {
+			n:        1,
+			expected: [4]int{},
+		},
+		"Three": {
+			n:        3,
+			expected: [4]int{1, 1, 1, 0},
+		},
+		"Fifteen": {
+			n:        15,
+			expected: [4]int{5, 5, 5, 0},
+		},
+		"MoreThanValid": {
+			n:        200,
+			expected: [4]int{100, 5, 5, 0},
+		},
+	}
+	for name, tt := range testCases {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			f := NewNormalSpace(tt.n, 4, WithSeed(1), WithExtractRemoved())
+			kept, removed, err := f.FilterIndice(pp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(kept)+len(removed) != pp.Points {
+				t.Fatalf("Expected %d indices in total, got %d", pp.Points, len(kept)+len(removed))
+			}
+			var cnt [4]int
+			for i, id := range kept {
+				if i > 0 && kept[i-1] >= id {
+					t.Fatalf("Kept indice must be in ascending order: %v", kept)
+				}
+				cnt[category(id)]++
+			}
+			if tt.n == 1 {
+				if len(kept) != 1 || cnt[3] != 0 {
+					t.Errorf("Expected one point with valid normal, got %v", kept)
+				}
+			} else if cnt != tt.expected {
+				t.Errorf("Expected number of the points in the categories: %v, got: %v", tt.expected, cnt)
+			}
+
+			// Kept indices can be used as ICP input.
+			it, err := pp.Vec3Iterator()
+			if err != nil {
+				t.Fatal(err)
+			}
+			ra := pc.NewIndiceVec3RandomAccessor(it, kept)
+			if ra.Len() != len(kept) {
+				t.Fatalf("Expected %d points, got %d", len(kept), ra.Len())
+			}
+		})
+	}
+
+	t.Run("Deterministic", func(t *testing.T) {
+		kept0, _, err := NewNormalSpace(20, 4, WithSeed(2)).FilterIndice(pp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		kept1, _, err := NewNormalSpace(20, 4, WithSeed(2)).FilterIndice(pp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(kept0, kept1) {
+			t.Error("Result with the same seed must be same")
+		}
+	})
+
+	t.Run("NonPositiveN", func(t *testing.T) {
+		for _, n := range []int{0, -1} {
+			kept, removed, err := NewNormalSpace(n, 4, WithExtractRemoved()).FilterIndice(pp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(kept) != 0 {
+				t.Errorf("n=%d: Expected no kept points, got %v", n, kept)
+			}
+			if len(removed) != pp.Points {
+				t.Errorf("n=%d: Expected %d removed points, got %d", n, pp.Points, len(removed))
+			}
+		}
+	})
+
+	t.Run("InvalidBins", func(t *testing.T) {
+		for _, bins := range []int{0, -1} {
+			if _, _, err := NewNormalSpace(3, bins).FilterIndice(pp); !errors.Is(err, ErrInvalidBins) {
+				t.Errorf("bins=%d: Expected error %v, got %v", bins, ErrInvalidBins, err)
+			}
+		}
+	})
+
+	t.Run("NoNormal", func(t *testing.T) {
+		if _, _, err := NewNormalSpace(3, 4).FilterIndice(pc.NewXYZPointCloud([]mat.Vec3{{0, 0, 0}})); err == nil {
+			t.Error("Expected error for the point cloud without normal")
+		}
+	})
+}
@@ -0,0 +1,22 @@
+package sampling
+
+type Options struct {
+	ExtractRemoved bool
+	Seed           int64
+}
+
+type Option func(*Options)
+
+// WithExtractRemoved makes FilterIndice also return the indice of the points not selected by the sampling.
+func WithExtractRemoved() Option {
+	return Option(func(o *Options) {
+		o.ExtractRemoved = true
+	})
+}
+
+// WithSeed sets the seed of the random number generator used by the random sampling filters.
+func WithSeed(seed int64) Option {
+	return Option(func(o *Options) {
+		o.Seed = seed
+	})
+}
@@ -0,0 +1,17 @@
+// Package sampling implements filters to sample the representative points.
+package sampling
+
+// removedIndice returns the indices in [0, n) which are not in kept.
+func removedIndice(n int, kept []int) []int {
+	selected := make([]bool, n)
+	for _, i := range kept {
+		selected[i] = true
+	}
+	removed := make([]int, 0, n-len(kept))
+	for i, s := range selected {
+		if !s {
+			removed = append(removed, i)
+		}
+	}
+	return removed
+}
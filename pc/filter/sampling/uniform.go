@@ -0,0 +1,83 @@
+package sampling
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/seqsense/pcgol/mat"
+	"github.com/seqsense/pcgol/pc"
+	"github.com/seqsense/pcgol/pc/filter"
+)
+
+// ErrInvalidLeafSize is returned by uniform sampling if the leaf size is not positive.
+var ErrInvalidLeafSize = errors.New("leaf size must be positive")
+
+type uniform struct {
+	Options
+
+	leafSize mat.Vec3
+}
+
+// NewUniform creates uniform sampling filter.
+// Space is divided into the voxels aligned to the origin
+// and the nearest point to the center of each voxel is kept.
+// Unlike voxel grid filter, the points are not modified.
+// Points with non-finite coordinate are never selected.
+// Kept indices are in ascending order.
+func NewUniform(leafSize mat.Vec3, opts ...Option) filter.IndiceFilter {
+	f := &uniform{leafSize: leafSize}
+	for _, o := range opts {
+		o(&f.Options)
+	}
+	return f
+}
+
+func (f *uniform) Filter(pp *pc.PointCloud) (*pc.PointCloud, error) {
+	return filter.FilterByIndice(f, pp)
+}
+
+type representative struct {
+	id     int
+	distSq float32
+}
+
+func (f *uniform) FilterIndice(pp *pc.PointCloud) ([]int, []int, error) {
+	for _, s := range f.leafSize {
+		if !(s > 0) || math.IsInf(float64(s), 0) {
+			return nil, nil, ErrInvalidLeafSize
+		}
+	}
+	it, err := pp.Vec3Iterator()
+	if err != nil {
+		return nil, nil, err
+	}
+	n := it.Len()
+	voxels := make(map[[3]int]representative)
+	for i := 0; i < n; i++ {
+		p := it.Vec3At(i)
+		if !p.IsFinite() {
+			continue
+		}
+		var key [3]int
+		var center mat.Vec3
+		for k := 0; k < 3; k++ {
+			key[k] = int(math.Floor(float64(p[k] / f.leafSize[k])))
+			center[k] = (float32(key[k]) + 0.5) * f.leafSize[k]
+		}
+		d := p.Sub(center).NormSq()
+		if r, ok := voxels[key]; !ok || d < r.distSq {
+			voxels[key] = representative{id: i, distSq: d}
+		}
+	}
+
+	kept := make([]int, 0, len(voxels))
+	for _, r := range voxels {
+		kept = append(kept, r.id)
+	}
+	sort.Ints(kept)
+	if f.ExtractRemoved {
+		return kept, removedIndice(n, kept), nil
+	}
+	return kept, nil, nil
+}
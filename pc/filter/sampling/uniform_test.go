@@ -0,0 +1,93 @@
+package sampling
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/seqsense/pcgol/mat"
+	"github.com/seqsense/pcgol/pc"
+	"github.com/seqsense/pcgol/pc/internal/pctest"
+)
+
+func TestUniform(t *testing.T) {
+	pp := pc.NewXYZPointCloud([]mat.Vec3{
+		{0.1, 0.1, 0.1},
+		{0.5, 0.5, 0.5},
+		{0.9, 0.4, 0.5},
+		{1.2, 0.5, 0.5},
+		{-0.5, -0.5, -0.5},
+		{-0.9, -0.9, -0.9},
+	})
+
+	testCases := map[string]struct {
+		leafSize        mat.Vec3
+		opts            []Option
+		expectedKept    []int
+		expectedRemoved []int
+	}{
+		"Default": {
+			leafSize:     mat.Vec3{1, 1, 1},
+			expectedKept: []int{1, 3, 4},
+		},
+		"ExtractRemoved": {
+			leafSize:        mat.Vec3{1, 1, 1},
+			opts:            []Option{WithExtractRemoved()},
+			expectedKept:    []int{1, 3, 4},
+			expectedRemoved: []int{0, 2, 5},
+		},
+		"Anisotropic": {
+			leafSize:     mat.Vec3{2, 1, 1},
+			expectedKept: []int{2, 4},
+		},
+	}
+	for name, tt := range testCases {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			f := NewUniform(tt.leafSize, tt.opts...)
+			pctest.CheckIndiceFilter(t, f, pp, tt.expectedKept, tt.expectedRemoved)
+		})
+	}
+}
+
+func TestUniform_edgeCases(t *testing.T) {
+	nan := float32(math.NaN())
+	inf := float32(math.Inf(1))
+
+	t.Run("NonFinitePoints", func(t *testing.T) {
+		pp := pc.NewXYZPointCloud([]mat.Vec3{
+			{nan, 0.5, 0.5},
+			{0.2, 0.2, 0.2},
+			{0.5, inf, 0.5},
+			{0.5, 0.5, 0.5},
+			{0.6, 0.5, 0.5},
+		})
+		f := NewUniform(mat.Vec3{1, 1, 1}, WithExtractRemoved())
+		pctest.CheckIndiceFilter(t, f, pp, []int{3}, []int{0, 1, 2, 4})
+	})
+
+	t.Run("Duplicates", func(t *testing.T) {
+		pp := pc.NewXYZPointCloud([]mat.Vec3{
+			{0.5, 0.5, 0.5},
+			{0.5, 0.5, 0.5},
+			{1.5, 0.5, 0.5},
+			{1.5, 0.5, 0.5},
+		})
+		f := NewUniform(mat.Vec3{1, 1, 1}, WithExtractRemoved())
+		pctest.CheckIndiceFilter(t, f, pp, []int{0, 2}, []int{1, 3})
+	})
+
+	t.Run("InvalidLeafSize", func(t *testing.T) {
+		pp := pc.NewXYZPointCloud([]mat.Vec3{{0, 0, 0}})
+		for _, leafSize := range []mat.Vec3{
+			{0, 1, 1},
+			{1, -1, 1},
+			{1, 1, nan},
+			{inf, 1, 1},
+		} {
+			if _, _, err := NewUniform(leafSize).FilterIndice(pp); !errors.Is(err, ErrInvalidLeafSize) {
+				t.Errorf("leafSize=%v: Expected error %v, got %v", leafSize, ErrInvalidLeafSize, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package voxelgrid
+
+import (
+	"math"
+
+	"github.com/seqsense/pcgol/mat"
+	"github.com/seqsense/pcgol/pc"
+)
+
+func (f *voxelGrid) filterHash(vMin mat.Vec3, it pc.Vec3RandomAccessor, pp *pc.PointCloud, rs []reducer) (*pc.PointCloud, error) {
+	addrs := make(map[[3]int]int)
+	voxels := make([]voxel, 0, initialSliceCap)
+	var vps []voxelPoint
+	for i := 0; i < it.Len(); i++ {
+		p := it.Vec3At(i).Sub(vMin)
+		key := [3]int{
+			int(math.Floor(float64(p[0] / f.LeafSize[0]))),
+			int(math.Floor(float64(p[1] / f.LeafSize[1]))),
+			int(math.Floor(float64(p[2] / f.LeafSize[2]))),
+		}
+		out, ok := addrs[key]
+		if !ok {
+			out = len(voxels)
+			addrs[key] = out
+			voxels = append(voxels, voxel{index: i, out: out})
+		}
+		v := &voxels[out]
+		v.num++
+		v.sum = v.sum.Add(p)
+		if len(rs) > 0 {
+			vps = append(vps, voxelPoint{out: out, raw: i})
+		}
+	}
+
+	n := len(voxels)
+	newPc := &pc.PointCloud{
+		PointCloudHeader: pp.Clone(),
+		Points:           n,
+		Data:             make([]byte, pp.Stride()*n),
+	}
+	newPc.Width = n
+	newPc.Height = 1
+	if n == 0 {
+		return newPc, nil
+	}
+	jt, err := newPc.Vec3Iterator()
+	if err != nil {
+		return nil, err
+	}
+	for j := range voxels {
+		v := &voxels[j]
+		pc.Copy(newPc, j, pp, v.index, 1)
+		if v.num > 1 {
+			jt.SetVec3(v.sum.Mul(1.0 / float32(v.num)).Add(vMin))
+		}
+		jt.Incr()
+	}
+
+	if len(rs) > 0 {
+		applyReducers(rs, newPc, pp, vps)
+	}
+	return newPc, nil
+}
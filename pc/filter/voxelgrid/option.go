@@ -5,8 +5,11 @@ import (
 )
 
 type Options struct {
-	LeafSize  mat.Vec3
-	ChunkSize [3]int
+	LeafSize   mat.Vec3
+	ChunkSize  [3]int
+	Reductions []FieldReduction
+	Hash       bool
+	Workers    int
 }
 
 type Option func(*Options)
@@ -16,3 +19,31 @@ func WithChunkSize(s [3]int) Option {
 		o.ChunkSize = s
 	})
 }
+
+// WithHash enables hash based voxel storage.
+// Memory usage is proportional to the number of the occupied voxels
+// instead of the bounding box of the point cloud.
+// Output points are ordered by the first appearance of the voxels
+// and ChunkSize is ignored.
+func WithHash() Option {
+	return Option(func(o *Options) {
+		o.Hash = true
+	})
+}
+
+// WithReduction sets the reduction method of the fields.
+// Positions (x, y, z) are averaged and other fields are taken from the first point in the voxel by default.
+// If the reductions are specified multiple times for the same field, the last one is used.
+func WithReduction(r Reduction, fields ...string) Option {
+	return Option(func(o *Options) {
+		o.Reductions = append(o.Reductions, FieldReduction{Fields: fields, Reduction: r})
+	})
+}
+
+// WithWorkers sets the number of goroutines to filter the chunks concurrently.
+// It is effective only if ChunkSize is set.
+func WithWorkers(n int) Option {
+	return Option(func(o *Options) {
+		o.Workers = n
+	})
+}
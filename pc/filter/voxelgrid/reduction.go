@@ -0,0 +1,246 @@
+package voxelgrid
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/seqsense/pcgol/pc"
+)
+
+var ErrUnsupportedField = errors.New("unsupported field for the reduction")
+
+// Reduction is a method to reduce the field values of the points in a voxel.
+type Reduction int
+
+const (
+	// ReductionFirst takes the value of the first point in the voxel.
+	ReductionFirst Reduction = iota
+	// ReductionMean takes the mean value.
+	ReductionMean
+	// ReductionMin takes the minimum value.
+	ReductionMin
+	// ReductionMax takes the maximum value.
+	ReductionMax
+	// ReductionMajority takes the most frequent value like labels.
+	// Values are compared in binary and ties are broken by the order of the points.
+	ReductionMajority
+	// ReductionNormalizedMean takes the mean vector of the fields and normalizes it.
+	// Fields are treated as elements of a vector like normal_x, normal_y, normal_z.
+	ReductionNormalizedMean
+)
+
+// FieldReduction specifies the reduction method of the fields.
+type FieldReduction struct {
+	Fields    []string
+	Reduction Reduction
+}
+
+type field struct {
+	offset int
+	size   int
+	typ    string
+}
+
+func (f field) get(data []byte) float64 {
+	b := data[f.offset : f.offset+f.size]
+	switch f.typ {
+	case "F":
+		if f.size == 8 {
+			return math.Float64frombits(binary.LittleEndian.Uint64(b))
+		}
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+	case "U":
+		switch f.size {
+		case 1:
+			return float64(b[0])
+		case 2:
+			return float64(binary.LittleEndian.Uint16(b))
+		default:
+			return float64(binary.LittleEndian.Uint32(b))
+		}
+	default:
+		switch f.size {
+		case 1:
+			return float64(int8(b[0]))
+		case 2:
+			return float64(int16(binary.LittleEndian.Uint16(b)))
+		default:
+			return float64(int32(binary.LittleEndian.Uint32(b)))
+		}
+	}
+}
+
+func (f field) set(data []byte, v float64) {
+	b := data[f.offset : f.offset+f.size]
+	switch f.typ {
+	case "F":
+		if f.size == 8 {
+			binary.LittleEndian.PutUint64(b, math.Float64bits(v))
+			return
+		}
+		binary.LittleEndian.PutUint32(b, math.Float32bits(float32(v)))
+		return
+	}
+	if f.typ == "U" && v < 0 {
+		v = 0
+	}
+	i := int64(math.Round(v))
+	switch f.size {
+	case 1:
+		b[0] = byte(i)
+	case 2:
+		binary.LittleEndian.PutUint16(b, uint16(i))
+	default:
+		binary.LittleEndian.PutUint32(b, uint32(i))
+	}
+}
+
+type reducer struct {
+	fields    []field
+	reduction Reduction
+}
+
+func newReducers(h *pc.PointCloudHeader, frs []FieldReduction) ([]reducer, error) {
+	offsets := make(map[string]field)
+	var offset int
+	for i, name := range h.Fields {
+		typ := "F"
+		if i < len(h.Type) && h.Type[i] != "" {
+			typ = h.Type[i]
+		}
+		if h.Count[i] == 1 {
+			offsets[name] = field{offset: offset, size: h.Size[i], typ: typ}
+		}
+		offset += h.Size[i] * h.Count[i]
+	}
+
+	rs := make([]reducer, 0, len(frs))
+	for _, fr := range frs {
+		r := reducer{reduction: fr.Reduction}
+		for _, name := range fr.Fields {
+			f, ok := offsets[name]
+			if !ok {
+				return nil, fmt.Errorf("%w: %s", ErrUnsupportedField, name)
+			}
+			switch {
+			case fr.Reduction == ReductionFirst || fr.Reduction == ReductionMajority:
+			case f.typ == "F" && (f.size == 4 || f.size == 8):
+			case (f.typ == "U" || f.typ == "I") && (f.size == 1 || f.size == 2 || f.size == 4):
+			default:
+				return nil, fmt.Errorf("%w: %s", ErrUnsupportedField, name)
+			}
+			r.fields = append(r.fields, f)
+		}
+		rs = append(rs, r)
+	}
+	return rs, nil
+}
+
+// reduce writes the reduced values of the src points to dst.
+// src must not be empty.
+func (r *reducer) reduce(dst []byte, src [][]byte) {
+	switch r.reduction {
+	case ReductionFirst:
+		for _, f := range r.fields {
+			copy(dst[f.offset:f.offset+f.size], src[0][f.offset:f.offset+f.size])
+		}
+	case ReductionMean:
+		for _, f := range r.fields {
+			var sum float64
+			for _, s := range src {
+				sum += f.get(s)
+			}
+			f.set(dst, sum/float64(len(src)))
+		}
+	case ReductionMin, ReductionMax:
+		for _, f := range r.fields {
+			v := f.get(src[0])
+			for _, s := range src[1:] {
+				vs := f.get(s)
+				if (r.reduction == ReductionMin) == (vs < v) {
+					v = vs
+				}
+			}
+			f.set(dst, v)
+		}
+	case ReductionMajority:
+		for _, f := range r.fields {
+			counts := make(map[string]int)
+			var best []byte
+			var bestCount int
+			for _, s := range src {
+				b := s[f.offset : f.offset+f.size]
+				c := counts[string(b)] + 1
+				counts[string(b)] = c
+				if c > bestCount {
+					best, bestCount = b, c
+				}
+			}
+			copy(dst[f.offset:f.offset+f.size], best)
+		}
+	case ReductionNormalizedMean:
+		sum := make([]float64, len(r.fields))
+		for _, s := range src {
+			for i, f := range r.fields {
+				sum[i] += f.get(s)
+			}
+		}
+		var normSq float64
+		for _, v := range sum {
+			normSq += v * v
+		}
+		if normSq == 0 {
+			for i, f := range r.fields {
+				f.set(dst, sum[i])
+			}
+			return
+		}
+		normInv := 1 / math.Sqrt(normSq)
+		for i, f := range r.fields {
+			f.set(dst, sum[i]*normInv)
+		}
+	}
+}
+
+// voxelPoint is a pair of the output index and the raw index of the input point.
+type voxelPoint struct {
+	out, raw int
+}
+
+type voxelPointSorter []voxelPoint
+
+func (s voxelPointSorter) Len() int {
+	return len(s)
+}
+
+func (s voxelPointSorter) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+
+func (s voxelPointSorter) Less(i, j int) bool {
+	return s[i].out < s[j].out
+}
+
+// applyReducers applies the reducers to the output points.
+func applyReducers(rs []reducer, newPc, pp *pc.PointCloud, vps []voxelPoint) {
+	sort.Stable(voxelPointSorter(vps))
+	stride := pp.Stride()
+	var src [][]byte
+	for begin := 0; begin < len(vps); {
+		out := vps[begin].out
+		src = src[:0]
+		end := begin
+		for ; end < len(vps) && vps[end].out == out; end++ {
+			raw := vps[end].raw * stride
+			src = append(src, pp.Data[raw:raw+stride])
+		}
+		dst := newPc.Data[out*stride : (out+1)*stride]
+		for i := range rs {
+			rs[i].reduce(dst, src)
+		}
+		begin = end
+	}
+}
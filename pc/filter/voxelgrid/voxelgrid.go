@@ -1,6 +1,9 @@
 package voxelgrid
 
 import (
+	"sync"
+	"sync/atomic"
+
 	"github.com/seqsense/pcgol/mat"
 	"github.com/seqsense/pcgol/pc"
 	"github.com/seqsense/pcgol/pc/filter"
@@ -10,14 +13,13 @@ const initialSliceCap = 256
 
 type voxelGrid struct {
 	Options
-
-	voxels []voxel
 }
 
 type voxel struct {
 	sum   mat.Vec3
 	num   int
 	index int
+	out   int
 }
 
 func New(leafSize mat.Vec3, opts ...Option) filter.Filter {
@@ -42,8 +44,16 @@ func (f *voxelGrid) Filter(pp *pc.PointCloud) (*pc.PointCloud, error) {
 	if err != nil {
 		return nil, err
 	}
+	rs, err := newReducers(&pp.PointCloudHeader, f.Reductions)
+	if err != nil {
+		return nil, err
+	}
+	if f.Hash {
+		return f.filterHash(vMin, it, pp, rs)
+	}
 	if f.ChunkSize[0]*f.ChunkSize[1]*f.ChunkSize[2] == 0 {
-		return f.filterChunk(vMin, vMax, it, pp)
+		var voxels []voxel
+		return f.filterChunk(&voxels, vMin, vMax, it, pp, rs)
 	}
 
 	size := vMax.Sub(vMin)
@@ -62,7 +72,7 @@ func (f *voxelGrid) Filter(pp *pc.PointCloud) (*pc.PointCloud, error) {
 	nx, ny, nz := int(size[0]/chunkSize[0])+1, int(size[1]/chunkSize[1])+1, int(size[2]/chunkSize[2])+1
 	nChunks := nx * ny * nz
 
-	outs := make([]*pc.PointCloud, 0, nChunks)
+	outs := make([]*pc.PointCloud, nChunks)
 	indices := make([][]int, nChunks)
 	nIndices := make([]int, nChunks)
 
@@ -78,11 +88,6 @@ func (f *voxelGrid) Filter(pp *pc.PointCloud) (*pc.PointCloud, error) {
 		return ((z*ny)+y)*nx + x
 	}
 
-	defer func() {
-		// Make large slice GC-ed ASAP
-		f.voxels = nil
-	}()
-
 	// Count points in each chunk and allocate indices
 	for i := 0; i < it.Len(); i++ {
 		cid := vec2cid(it.Vec3At(i).Sub(vMin))
@@ -99,26 +104,52 @@ func (f *voxelGrid) Filter(pp *pc.PointCloud) (*pc.PointCloud, error) {
 	}
 
 	// Apply filter to the chunks
-	for cid, indice := range indices {
-		if len(indice) == 0 {
-			continue
+	errs := make([]error, nChunks)
+	filterChunks := func(voxels *[]voxel, next func() int) {
+		for cid := next(); cid < nChunks; cid = next() {
+			indice := indices[cid]
+			if len(indice) == 0 {
+				continue
+			}
+			iit := pc.NewVec3RandomAccessorIterator(
+				pc.NewIndiceVec3RandomAccessor(it, indice),
+			)
+			cp := cid2xyz(cid)
+			vcMin := vMin.Add(cp.ElementMul(chunkSize))
+			outs[cid], errs[cid] = f.filterChunk(voxels, vcMin, chunkSize, iit, pp, rs)
+		}
+	}
+	var next int64 = -1
+	nextCid := func() int {
+		return int(atomic.AddInt64(&next, 1))
+	}
+	if f.Workers < 2 {
+		var voxels []voxel
+		filterChunks(&voxels, nextCid)
+	} else {
+		var wg sync.WaitGroup
+		wg.Add(f.Workers)
+		for w := 0; w < f.Workers; w++ {
+			go func() {
+				defer wg.Done()
+				var voxels []voxel
+				filterChunks(&voxels, nextCid)
+			}()
 		}
-		iit := pc.NewVec3RandomAccessorIterator(
-			pc.NewIndiceVec3RandomAccessor(it, indice),
-		)
-		cp := cid2xyz(cid)
-		vcMin := vMin.Add(cp.ElementMul(chunkSize))
-		out, err := f.filterChunk(vcMin, chunkSize, iit, pp)
+		wg.Wait()
+	}
+	for _, err := range errs {
 		if err != nil {
 			return nil, err
 		}
-		outs = append(outs, out)
 	}
 
 	// Combine the outputs
 	var n int
 	for _, out := range outs {
-		n += out.Width * out.Height
+		if out != nil {
+			n += out.Width * out.Height
+		}
 	}
 	newPc := &pc.PointCloud{
 		PointCloudHeader: pp.Clone(),
@@ -128,27 +159,38 @@ func (f *voxelGrid) Filter(pp *pc.PointCloud) (*pc.PointCloud, error) {
 	newPc.Width = n
 	newPc.Height = 1
 	for _, out := range outs {
-		newPc.Data = append(newPc.Data, out.Data...)
+		if out != nil {
+			newPc.Data = append(newPc.Data, out.Data...)
+		}
 	}
 	return newPc, nil
 }
 
-func (f *voxelGrid) filterChunk(vMin, size mat.Vec3, it pc.Vec3ConstForwardIterator, pp *pc.PointCloud) (*pc.PointCloud, error) {
+// filterChunk filters the points in the chunk using voxels as a work buffer.
+func (f *voxelGrid) filterChunk(voxels *[]voxel, vMin, size mat.Vec3, it pc.Vec3ConstForwardIterator, pp *pc.PointCloud, rs []reducer) (*pc.PointCloud, error) {
 	xs, ys, zs := int(size[0]/f.LeafSize[0]), int(size[1]/f.LeafSize[1]), int(size[2]/f.LeafSize[2])
 	nVoxels := (xs + 1) * (ys + 1) * (zs + 1)
-	if len(f.voxels) < nVoxels {
-		f.voxels = make([]voxel, nVoxels)
+	if cap(*voxels) < nVoxels {
+		*voxels = make([]voxel, nVoxels)
 	} else {
-		for i := range f.voxels {
-			f.voxels[i] = voxel{}
+		*voxels = (*voxels)[:nVoxels]
+		for i := range *voxels {
+			(*voxels)[i] = voxel{}
 		}
 	}
+	vs := *voxels
 
 	var n int
+	var vps []voxelPoint
 	for ; it.IsValid(); it.Incr() {
 		p := it.Vec3().Sub(vMin)
 		x, y, z := int(p[0]/f.LeafSize[0]), int(p[1]/f.LeafSize[1]), int(p[2]/f.LeafSize[2])
-		v := &f.voxels[x+xs*(y+ys*z)]
+		addr := x + (xs+1)*(y+(ys+1)*z)
+		if len(rs) > 0 {
+			// Store voxel address at first and convert it to output index later
+			vps = append(vps, voxelPoint{out: addr, raw: it.RawIndex()})
+		}
+		v := &vs[addr]
 		if v.num == 0 {
 			v.index = it.RawIndex()
 			n++
@@ -170,9 +212,10 @@ func (f *voxelGrid) filterChunk(vMin, size mat.Vec3, it pc.Vec3ConstForwardItera
 	}
 	var jStart int
 	stride := pp.Stride()
-	for i := range f.voxels {
-		v := &f.voxels[i]
+	for i := range vs {
+		v := &vs[i]
 		if n := v.num; n > 0 {
+			v.out = jStart / stride
 			iStart := v.index * stride
 			copy(newPc.Data[jStart:jStart+stride], pp.Data[iStart:iStart+stride])
 			if n > 1 {
@@ -183,5 +226,11 @@ func (f *voxelGrid) filterChunk(vMin, size mat.Vec3, it pc.Vec3ConstForwardItera
 		}
 	}
 
+	if len(rs) > 0 {
+		for i := range vps {
+			vps[i].out = vs[vps[i].out].out
+		}
+		applyReducers(rs, newPc, pp, vps)
+	}
 	return newPc, nil
 }
@@ -1,7 +1,11 @@
 package voxelgrid
 
 import (
+	"errors"
 	"math"
+	"math/rand"
+	"reflect"
+	"sync"
 	"testing"
 
 	"github.com/seqsense/pcgol/mat"
@@ -51,6 +55,31 @@ func TestVoxelGrid(t *testing.T) {
 				1, 6, 4, 2,
 			},
 		},
+		"WithChunkSize333Workers": {
+			opts: []Option{WithChunkSize([3]int{3, 3, 3}), WithWorkers(4)},
+			expected: []mat.Vec3{
+				{0.6375, 1.8750, 0.1375},
+				{0.0000, 3.0000, 0.0000},
+				{1.2500, 0.0000, 1.2500},
+				{1.2500, 1.2625, 1.2500},
+			},
+			expectedLabels: []uint32{
+				1, 6, 4, 2,
+			},
+		},
+		"WithHash": {
+			// Ordered by the first appearance
+			opts: []Option{WithHash()},
+			expected: []mat.Vec3{
+				{0.6375, 1.8750, 0.1375},
+				{1.2500, 1.2625, 1.2500},
+				{1.2500, 0.0000, 1.2500},
+				{0.0000, 3.0000, 0.0000},
+			},
+			expectedLabels: []uint32{
+				1, 2, 4, 6,
+			},
+		},
 	}
 
 	for name, tt := range testCases {
@@ -108,3 +137,272 @@ func TestVoxelGrid(t *testing.T) {
 		})
 	}
 }
+
+func TestVoxelGrid_WithReduction(t *testing.T) {
+	pp := pc.PointCloud{
+		PointCloudHeader: pc.PointCloudHeader{
+			Fields: []string{"x", "y", "z", "intensity", "label", "normal_x", "normal_y", "normal_z"},
+			Size:   []int{4, 4, 4, 4, 4, 4, 4, 4},
+			Type:   []string{"F", "F", "F", "F", "U", "F", "F", "F"},
+			Count:  []int{1, 1, 1, 1, 1, 1, 1, 1},
+			Width:  4,
+			Height: 1,
+		},
+		Points: 4,
+		Data: float.Float32SliceAsByteSlice([]float32{
+			0.01, 0.01, 0.01, 10, math.Float32frombits(1), 1, 0, 0,
+			0.02, 0.02, 0.02, 20, math.Float32frombits(2), 0, 1, 0,
+			0.03, 0.03, 0.03, 60, math.Float32frombits(2), 0, 1, 0,
+			1.01, 1.01, 1.01, 5, math.Float32frombits(3), 0, 0, 1,
+		}),
+	}
+
+	testCases := map[string]struct {
+		opts              []Option
+		expectedIntensity []float32
+		expectedLabel     []uint32
+		expectedNormal    []mat.Vec3
+	}{
+		"Default": {
+			expectedIntensity: []float32{10, 5},
+			expectedLabel:     []uint32{1, 3},
+			expectedNormal:    []mat.Vec3{{1, 0, 0}, {0, 0, 1}},
+		},
+		"Mean": {
+			opts: []Option{
+				WithReduction(ReductionMean, "intensity", "normal_x", "normal_y", "normal_z"),
+				WithReduction(ReductionMajority, "label"),
+			},
+			expectedIntensity: []float32{30, 5},
+			expectedLabel:     []uint32{2, 3},
+			expectedNormal:    []mat.Vec3{{1.0 / 3, 2.0 / 3, 0}, {0, 0, 1}},
+		},
+		"MaxMin": {
+			opts: []Option{
+				WithReduction(ReductionMax, "intensity"),
+				WithReduction(ReductionMin, "label"),
+				WithReduction(ReductionNormalizedMean, "normal_x", "normal_y", "normal_z"),
+			},
+			expectedIntensity: []float32{60, 5},
+			expectedLabel:     []uint32{1, 3},
+			expectedNormal:    []mat.Vec3{{1 / float32(math.Sqrt(5)), 2 / float32(math.Sqrt(5)), 0}, {0, 0, 1}},
+		},
+		"Min": {
+			opts: []Option{
+				WithReduction(ReductionMin, "intensity"),
+				WithReduction(ReductionMax, "label"),
+				WithChunkSize([3]int{4, 4, 4}),
+			},
+			expectedIntensity: []float32{10, 5},
+			expectedLabel:     []uint32{2, 3},
+			expectedNormal:    []mat.Vec3{{1, 0, 0}, {0, 0, 1}},
+		},
+		"HashMean": {
+			opts: []Option{
+				WithHash(),
+				WithReduction(ReductionMean, "intensity"),
+				WithReduction(ReductionMajority, "label"),
+			},
+			expectedIntensity: []float32{30, 5},
+			expectedLabel:     []uint32{2, 3},
+			expectedNormal:    []mat.Vec3{{1, 0, 0}, {0, 0, 1}},
+		},
+	}
+	for name, tt := range testCases {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			out, err := New(mat.Vec3{0.1, 0.1, 0.1}, tt.opts...).Filter(&pp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out.Points != 2 {
+				t.Fatalf("Expected 2 points, got %d", out.Points)
+			}
+			it, err := out.Vec3Iterator()
+			if err != nil {
+				t.Fatal(err)
+			}
+			its, err := out.Float32Iterators("intensity", "normal_x", "normal_y", "normal_z")
+			if err != nil {
+				t.Fatal(err)
+			}
+			lt, err := out.Uint32Iterator("label")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p := it.Vec3At(0); !p.Equal(mat.Vec3{0.02, 0.02, 0.02}) {
+				t.Errorf("Expected position: %v, got: %v", mat.Vec3{0.02, 0.02, 0.02}, p)
+			}
+			for i := 0; i < 2; i++ {
+				if v := its[0].Float32At(i); v != tt.expectedIntensity[i] {
+					t.Errorf("Expected intensity: %f, got: %f", tt.expectedIntensity[i], v)
+				}
+				if l := lt.Uint32At(i); l != tt.expectedLabel[i] {
+					t.Errorf("Expected label: %d, got: %d", tt.expectedLabel[i], l)
+				}
+				n := mat.Vec3{its[1].Float32At(i), its[2].Float32At(i), its[3].Float32At(i)}
+				if !n.Equal(tt.expectedNormal[i]) {
+					t.Errorf("Expected normal: %v, got: %v", tt.expectedNormal[i], n)
+				}
+			}
+		})
+	}
+
+	t.Run("InvalidField", func(t *testing.T) {
+		_, err := New(mat.Vec3{0.1, 0.1, 0.1}, WithReduction(ReductionMean, "rgb")).Filter(&pp)
+		if !errors.Is(err, ErrUnsupportedField) {
+			t.Errorf("Expected error: %v, got: %v", ErrUnsupportedField, err)
+		}
+	})
+}
+
+func TestVoxelGrid_rowBoundary(t *testing.T) {
+	// Voxels at the end of a row must not be merged with the beginning of the next row.
+	pp := &pc.PointCloud{
+		PointCloudHeader: pc.PointCloudHeader{
+			Fields: []string{"x", "y", "z"},
+			Size:   []int{4, 4, 4},
+			Count:  []int{1, 1, 1},
+			Width:  3,
+			Height: 1,
+		},
+		Points: 3,
+		Data: float.Float32SliceAsByteSlice([]float32{
+			0, 0, 0,
+			1, 0, 0,
+			0, 0.5, 0,
+		}),
+	}
+	out, err := New(mat.Vec3{0.5, 0.5, 0.5}).Filter(pp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Points != 3 {
+		t.Fatalf("Expected 3 points, got %d", out.Points)
+	}
+
+	t.Run("Random", func(t *testing.T) {
+		pp := randomPointCloud(10000)
+		leafSize := mat.Vec3{0.5, 0.5, 0.5}
+		out, err := New(leafSize).Filter(pp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkVoxelGridOutput(t, pp, out, leafSize)
+	})
+}
+
+// naiveVoxelGrid returns the expected output points of the voxel grid filter.
+func naiveVoxelGrid(ra pc.Vec3RandomAccessor, leafSize mat.Vec3) map[mat.Vec3]bool {
+	vMin, _, err := pc.MinMaxVec3(ra)
+	if err != nil {
+		panic(err)
+	}
+	type sum struct {
+		first mat.Vec3
+		sum   mat.Vec3
+		num   int
+	}
+	voxels := make(map[[3]int]*sum)
+	for i := 0; i < ra.Len(); i++ {
+		p := ra.Vec3At(i)
+		d := p.Sub(vMin)
+		key := [3]int{int(d[0] / leafSize[0]), int(d[1] / leafSize[1]), int(d[2] / leafSize[2])}
+		v, ok := voxels[key]
+		if !ok {
+			v = &sum{first: p}
+			voxels[key] = v
+		}
+		v.sum = v.sum.Add(d)
+		v.num++
+	}
+	out := make(map[mat.Vec3]bool)
+	for _, v := range voxels {
+		if v.num == 1 {
+			out[v.first] = true
+			continue
+		}
+		out[v.sum.Mul(1.0/float32(v.num)).Add(vMin)] = true
+	}
+	return out
+}
+
+func randomPointCloud(n int) *pc.PointCloud {
+	data := make([]float32, 0, n*3)
+	for i := 0; i < n; i++ {
+		data = append(data, rand.Float32()*10, rand.Float32()*10, rand.Float32())
+	}
+	return &pc.PointCloud{
+		PointCloudHeader: pc.PointCloudHeader{
+			Fields: []string{"x", "y", "z"},
+			Size:   []int{4, 4, 4},
+			Count:  []int{1, 1, 1},
+			Width:  n,
+			Height: 1,
+		},
+		Points: n,
+		Data:   float.Float32SliceAsByteSlice(data),
+	}
+}
+
+func checkVoxelGridOutput(t *testing.T, pp, out *pc.PointCloud, leafSize mat.Vec3) {
+	t.Helper()
+	it, err := pp.Vec3Iterator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := naiveVoxelGrid(it, leafSize)
+	if out.Points != len(expected) {
+		t.Fatalf("Expected %d points, got %d", len(expected), out.Points)
+	}
+	oit, err := out.Vec3Iterator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < oit.Len(); i++ {
+		if p := oit.Vec3At(i); !expected[p] {
+			t.Errorf("Unexpected point %v", p)
+		}
+	}
+}
+
+func TestVoxelGrid_WithHash(t *testing.T) {
+	pp := randomPointCloud(10000)
+	leafSize := mat.Vec3{0.5, 0.5, 0.5}
+
+	out, err := New(leafSize, WithHash()).Filter(pp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkVoxelGridOutput(t, pp, out, leafSize)
+}
+
+func TestVoxelGrid_WithWorkers(t *testing.T) {
+	pp := randomPointCloud(10000)
+	leafSize := mat.Vec3{0.1, 0.1, 0.1}
+	chunkSize := WithChunkSize([3]int{8, 8, 8})
+
+	expected, err := New(leafSize, chunkSize).Filter(pp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Filter must be safe for concurrent use.
+	f := New(leafSize, chunkSize, WithWorkers(4))
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			out, err := f.Filter(pp)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if !reflect.DeepEqual(expected.Data, out.Data) {
+				t.Error("Output with workers differs")
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -3,6 +3,8 @@ package float
 import (
 	"reflect"
 	"unsafe"
+
+	"github.com/seqsense/pcgol/mat"
 )
 
 func ByteSliceAsFloat32Slice(b []byte) []float32 {
@@ -34,3 +36,16 @@ func Float32SliceAsByteSlice(f []float32) []byte {
 func IsShadowing(b []byte, f []float32) bool {
 	return uintptr(unsafe.Pointer(&f[0])) != uintptr(unsafe.Pointer(&b[0]))
 }
+
+func Vec3SliceAsByteSlice(v []mat.Vec3) []byte {
+	n := len(v) * 4 * 3
+
+	up := unsafe.Pointer(&(v[0]))
+	pi := (*[1]byte)(up)
+	buf := (*pi)[:]
+	sh := (*reflect.SliceHeader)(unsafe.Pointer(&buf))
+	sh.Len = n
+	sh.Cap = n
+
+	return buf
+}
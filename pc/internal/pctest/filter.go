@@ -0,0 +1,43 @@
+package pctest
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/seqsense/pcgol/pc"
+	"github.com/seqsense/pcgol/pc/filter"
+)
+
+// CheckIndiceFilter checks that FilterIndice of f returns the expected indice
+// and Filter of f returns the unmodified input points at the expected kept indice.
+// Empty kept indice is accepted regardless of being nil.
+func CheckIndiceFilter(t *testing.T, f filter.IndiceFilter, pp *pc.PointCloud, expectedKept, expectedRemoved []int) {
+	t.Helper()
+
+	kept, removed, err := f.FilterIndice(pp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if (len(expectedKept) != 0 || len(kept) != 0) && !reflect.DeepEqual(expectedKept, kept) {
+		t.Errorf("Expected kept indice: %v, got: %v", expectedKept, kept)
+	}
+	if !reflect.DeepEqual(expectedRemoved, removed) {
+		t.Errorf("Expected removed indice: %v, got: %v", expectedRemoved, removed)
+	}
+
+	out, err := f.Filter(pp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := len(expectedKept)
+	if out.Points != n || out.Width != n || out.Height != 1 {
+		t.Fatalf("Expected %dx1 points, got %dx%d (%d points)", n, out.Width, out.Height, out.Points)
+	}
+	stride := pp.Stride()
+	for i, id := range expectedKept {
+		if !bytes.Equal(pp.Data[id*stride:(id+1)*stride], out.Data[i*stride:(i+1)*stride]) {
+			t.Errorf("Expected output point %d to be input point %d", i, id)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+// Package pctest provides utilities for testing point cloud processing.
+package pctest
+
+import (
+	"math/rand"
+
+	"github.com/seqsense/pcgol/mat"
+	"github.com/seqsense/pcgol/pc"
+)
+
+// RandomCloud returns n points uniformly distributed in the cube [min, max)^3.
+func RandomCloud(n int, min, max float32) pc.Vec3Slice {
+	width := max - min
+	ra := make(pc.Vec3Slice, n)
+	for i := range ra {
+		ra[i] = mat.Vec3{
+			min + rand.Float32()*width,
+			min + rand.Float32()*width,
+			min + rand.Float32()*width,
+		}
+	}
+	return ra
+}
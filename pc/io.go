@@ -154,6 +154,17 @@ func unmarshalPCDDataTo(rb *bufio.Reader, pp *PointCloud, fmt Format) error {
 				for j := 0; j < pp.Count[i]; j++ {
 					switch f {
 					case "F":
+						if pp.Size[i] == 8 {
+							v, err := strconv.ParseFloat(pointData[lineOffset+j], 64)
+							if err != nil {
+								return err
+							}
+							b := math.Float64bits(v)
+							binary.LittleEndian.PutUint64(
+								pp.Data[dataOffset:dataOffset+8], b,
+							)
+							break
+						}
 						v, err := strconv.ParseFloat(pointData[lineOffset+j], 32)
 						if err != nil {
 							return err
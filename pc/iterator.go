@@ -61,6 +61,42 @@ type Vec3ConstForwardIterator interface {
 	RawIndex() int
 }
 
+type Float64Iterator interface {
+	Float64RandomAccessor
+	Float64ForwardIterator
+}
+
+type Float64ForwardIterator interface {
+	Float64ConstForwardIterator
+	SetFloat64(float64)
+}
+
+type Float64ConstForwardIterator interface {
+	Incr()
+	IsValid() bool
+	Float64() float64
+	// RawIndex returns the index of the current item on the base PointCloud storage
+	RawIndex() int
+}
+
+type Vec3dIterator interface {
+	Vec3dRandomAccessor
+	Vec3dForwardIterator
+}
+
+type Vec3dForwardIterator interface {
+	Vec3dConstForwardIterator
+	SetVec3d(mat.Vec3d)
+}
+
+type Vec3dConstForwardIterator interface {
+	Incr()
+	IsValid() bool
+	Vec3d() mat.Vec3d
+	// RawIndex returns the index of the current item on the base PointCloud storage
+	RawIndex() int
+}
+
 type binaryFloat32Iterator struct {
 	binaryIterator
 }
@@ -186,6 +222,76 @@ func (i naiveVec3Iterator) RawIndexAt(j int) int {
 	return i[0].RawIndexAt(j)
 }
 
+type binaryFloat64Iterator struct {
+	binaryIterator
+}
+
+func (i *binaryFloat64Iterator) Float64() float64 {
+	return math.Float64frombits(
+		binary.LittleEndian.Uint64(i.binaryIterator.data[i.binaryIterator.pos : i.binaryIterator.pos+8]),
+	)
+}
+
+func (i *binaryFloat64Iterator) Float64At(j int) float64 {
+	pos := i.binaryIterator.pos + i.stride*j
+	return math.Float64frombits(
+		binary.LittleEndian.Uint64(i.binaryIterator.data[pos : pos+8]),
+	)
+}
+
+func (i *binaryFloat64Iterator) SetFloat64(v float64) {
+	b := math.Float64bits(v)
+	binary.LittleEndian.PutUint64(
+		i.binaryIterator.data[i.binaryIterator.pos:i.binaryIterator.pos+8], b,
+	)
+}
+
+func (i *binaryFloat64Iterator) IsValid() bool {
+	return i.pos+8 <= len(i.data)
+}
+
+func (i *binaryFloat64Iterator) RawIndexAt(j int) int {
+	return i.RawIndex() + j
+}
+
+type naiveVec3dIterator [3]Float64Iterator
+
+func (i naiveVec3dIterator) IsValid() bool {
+	return i[0].IsValid()
+}
+
+func (i naiveVec3dIterator) Len() int {
+	return i[0].Len()
+}
+
+func (i naiveVec3dIterator) Incr() {
+	i[0].Incr()
+	i[1].Incr()
+	i[2].Incr()
+}
+
+func (i naiveVec3dIterator) Vec3d() mat.Vec3d {
+	return mat.Vec3d{i[0].Float64(), i[1].Float64(), i[2].Float64()}
+}
+
+func (i naiveVec3dIterator) Vec3dAt(j int) mat.Vec3d {
+	return mat.Vec3d{i[0].Float64At(j), i[1].Float64At(j), i[2].Float64At(j)}
+}
+
+func (i naiveVec3dIterator) SetVec3d(v mat.Vec3d) {
+	i[0].SetFloat64(v[0])
+	i[1].SetFloat64(v[1])
+	i[2].SetFloat64(v[2])
+}
+
+func (i naiveVec3dIterator) RawIndex() int {
+	return i[0].RawIndex()
+}
+
+func (i naiveVec3dIterator) RawIndexAt(j int) int {
+	return i[0].RawIndexAt(j)
+}
+
 type Uint32Iterator interface {
 	Uint32RandomAccessor
 	Uint32ForwardIterator
@@ -672,3 +672,108 @@ func BenchmarkFloat32Iterator(b *testing.B) {
 		}
 	})
 }
+
+func TestVec3dIterator(t *testing.T) {
+	pp, err := Unmarshal(bytes.NewReader([]byte(`# .PCD v0.7 - Point Cloud Data file format
+VERSION 0.7
+FIELDS x y z label
+SIZE 8 8 8 4
+TYPE F F F U
+COUNT 1 1 1 1
+WIDTH 2
+HEIGHT 1
+VIEWPOINT 0 0 0 1 0 0 0
+POINTS 2
+DATA ascii
+500000.01 4000000.02 10.03 1
+500000.02 4000000.04 10.06 2
+`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	it, err := pp.Vec3dIterator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []mat.Vec3d{
+		{500000.01, 4000000.02, 10.03},
+		{500000.02, 4000000.04, 10.06},
+	}
+	if n := it.Len(); n != len(expected) {
+		t.Fatalf("Expected len: %d, got: %d", len(expected), n)
+	}
+	for i, e := range expected {
+		if !it.IsValid() {
+			t.Fatalf("Iterator is invalid at position %d", i)
+		}
+		if v := it.Vec3d(); !v.Equal(e) {
+			t.Errorf("Expected: %v, got: %v", e, v)
+		}
+		if v := it.Vec3dAt(0); !v.Equal(e) {
+			t.Errorf("Expected: %v, got: %v", e, v)
+		}
+		it.Incr()
+	}
+	if it.IsValid() {
+		t.Fatal("Iterator must be invalid at the end")
+	}
+
+	lt, err := pp.Uint32Iterator("label")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := lt.Uint32At(1); l != 2 {
+		t.Errorf("Expected label: 2, got: %d", l)
+	}
+
+	t.Run("SetVec3d", func(t *testing.T) {
+		it, err := pp.Vec3dIterator()
+		if err != nil {
+			t.Fatal(err)
+		}
+		v := mat.Vec3d{123456.789, 9876543.21, 1.5}
+		it.SetVec3d(v)
+		if v2 := it.Vec3dAt(0); !v2.Equal(v) {
+			t.Errorf("Expected: %v, got: %v", v, v2)
+		}
+	})
+
+	t.Run("Float32Field", func(t *testing.T) {
+		pp := &PointCloud{
+			PointCloudHeader: PointCloudHeader{
+				Fields: []string{"x", "y", "z"},
+				Size:   []int{4, 4, 4},
+				Count:  []int{1, 1, 1},
+				Width:  1,
+				Height: 1,
+			},
+			Points: 1,
+			Data:   make([]byte, 4*3),
+		}
+		if _, err := pp.Vec3dIterator(); err == nil {
+			t.Error("Expected error for float32 fields")
+		}
+	})
+
+	t.Run("Int64Field", func(t *testing.T) {
+		pp := &PointCloud{
+			PointCloudHeader: PointCloudHeader{
+				Fields: []string{"x", "y", "z", "timestamp"},
+				Size:   []int{8, 8, 8, 8},
+				Type:   []string{"F", "F", "F", "I"},
+				Count:  []int{1, 1, 1, 1},
+				Width:  1,
+				Height: 1,
+			},
+			Points: 1,
+			Data:   make([]byte, 8*4),
+		}
+		if _, err := pp.Float64Iterator("x"); err != nil {
+			t.Errorf("Unexpected error for float64 field: %v", err)
+		}
+		if _, err := pp.Float64Iterator("timestamp"); err == nil {
+			t.Error("Expected error for int64 field")
+		}
+	})
+}
@@ -3,6 +3,7 @@ package pc
 import (
 	"errors"
 
+	"github.com/seqsense/pcgol/mat"
 	"github.com/seqsense/pcgol/pc/internal/float"
 )
 
@@ -69,6 +70,15 @@ func (pp *PointCloudHeader) Stride() int {
 	return stride
 }
 
+// ViewpointOrigin returns the sensor origin stored in Viewpoint.
+// Zero vector is returned if Viewpoint is not set.
+func (pp *PointCloudHeader) ViewpointOrigin() mat.Vec3 {
+	if len(pp.Viewpoint) < 3 {
+		return mat.Vec3{}
+	}
+	return mat.Vec3{pp.Viewpoint[0], pp.Viewpoint[1], pp.Viewpoint[2]}
+}
+
 type PointCloud struct {
 	PointCloudHeader
 	Points int
@@ -87,6 +97,29 @@ func Copy(dst *PointCloud, dstIndex int, src *PointCloud, srcIndex, n int) {
 	copy(dst.Data[di:di+nb], src.Data[si:si+nb])
 }
 
+// NewXYZPointCloud creates an unorganized PointCloud with x, y, z fields of the points.
+// The PointCloud takes ownership of points and shares the memory with it.
+func NewXYZPointCloud(points []mat.Vec3) *PointCloud {
+	n := len(points)
+	pp := &PointCloud{
+		PointCloudHeader: PointCloudHeader{
+			Version: 0.7,
+			Fields:  []string{"x", "y", "z"},
+			Size:    []int{4, 4, 4},
+			Type:    []string{"F", "F", "F"},
+			Count:   []int{1, 1, 1},
+			Width:   n,
+			Height:  1,
+		},
+		Points: n,
+		Data:   []byte{},
+	}
+	if n > 0 {
+		pp.Data = float.Vec3SliceAsByteSlice(points)
+	}
+	return pp
+}
+
 func (pp *PointCloud) Float32Iterator(name string) (Float32Iterator, error) {
 	offset := 0
 	for i, fn := range pp.Fields {
@@ -170,6 +203,43 @@ func (pp *PointCloud) naiveVec3Iterator() (Vec3Iterator, error) {
 	return naiveVec3Iterator{its[0], its[1], its[2]}, nil
 }
 
+// Float64Iterator returns iterator of the float64 field.
+// The field must be 8 bytes and its type must be F if Type is set.
+func (pp *PointCloud) Float64Iterator(name string) (Float64Iterator, error) {
+	offset := 0
+	for i, fn := range pp.Fields {
+		if fn == name {
+			if pp.Size[i] != 8 || (i < len(pp.Type) && pp.Type[i] != "F") {
+				return nil, errors.New("field is not float64")
+			}
+			return &binaryFloat64Iterator{
+				binaryIterator: binaryIterator{
+					data:   pp.Data,
+					pos:    offset,
+					stride: pp.Stride(),
+				},
+			}, nil
+		}
+		offset += pp.Size[i] * pp.Count[i]
+	}
+	return nil, errors.New("invalid field name")
+}
+
+// Vec3dIterator returns float64 position iterator of x, y, z fields.
+// The fields must be 8 bytes.
+// Use NewOffsetVec3dRandomAccessor for float32 fields.
+func (pp *PointCloud) Vec3dIterator() (Vec3dIterator, error) {
+	var its naiveVec3dIterator
+	for i, name := range []string{"x", "y", "z"} {
+		it, err := pp.Float64Iterator(name)
+		if err != nil {
+			return nil, err
+		}
+		its[i] = it
+	}
+	return its, nil
+}
+
 func (pp *PointCloud) Uint32Iterator(name string) (Uint32Iterator, error) {
 	offset := 0
 	for i, fn := range pp.Fields {
@@ -132,3 +132,44 @@ func TestCopy(t *testing.T) {
 		t.Errorf("Expected data: %v, got: %v", bytesExpected, pp1.Data)
 	}
 }
+
+func TestNewXYZPointCloud(t *testing.T) {
+	testCases := map[string][]mat.Vec3{
+		"Empty":  {},
+		"Points": {{1, 2, 3}, {4, 5, 6}},
+	}
+	for name, points := range testCases {
+		points := points
+		t.Run(name, func(t *testing.T) {
+			pp := NewXYZPointCloud(points)
+			if pp.Points != len(points) || pp.Width != len(points) || pp.Height != 1 {
+				t.Fatalf("Expected %d points, got %d (%dx%d)", len(points), pp.Points, pp.Width, pp.Height)
+			}
+			if len(pp.Data) != len(points)*pp.Stride() {
+				t.Fatalf("Expected %d bytes, got %d", len(points)*pp.Stride(), len(pp.Data))
+			}
+			if len(points) == 0 {
+				return
+			}
+			it, err := pp.Vec3Iterator()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, p := range points {
+				if v := it.Vec3At(i); !v.Equal(p) {
+					t.Errorf("Expected point %d: %v, got: %v", i, p, v)
+				}
+			}
+		})
+	}
+}
+
+func TestPointCloudHeader_ViewpointOrigin(t *testing.T) {
+	h := PointCloudHeader{Viewpoint: []float32{1, 2, 3, 1, 0, 0, 0}}
+	if o := h.ViewpointOrigin(); !o.Equal(mat.Vec3{1, 2, 3}) {
+		t.Errorf("Expected %v, got %v", mat.Vec3{1, 2, 3}, o)
+	}
+	if o := (&PointCloudHeader{}).ViewpointOrigin(); !o.Equal(mat.Vec3{}) {
+		t.Errorf("Expected %v, got %v", mat.Vec3{}, o)
+	}
+}
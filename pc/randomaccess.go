@@ -18,6 +18,20 @@ type Float32RandomAccessor interface {
 	RawIndexAt(int) int
 }
 
+type Vec3dRandomAccessor interface {
+	Vec3dAt(int) mat.Vec3d
+	Len() int
+	// RawIndexAt returns the index of the specific item on the base PointCloud storage
+	RawIndexAt(int) int
+}
+
+type Float64RandomAccessor interface {
+	Float64At(int) float64
+	Len() int
+	// RawIndexAt returns the index of the specific item on the base PointCloud storage
+	RawIndexAt(int) int
+}
+
 type Uint32RandomAccessor interface {
 	Uint32At(int) uint32
 	Len() int
@@ -51,3 +65,21 @@ func (i *vec3RandomAccessorIterator) Vec3() mat.Vec3 {
 func (i *vec3RandomAccessorIterator) RawIndex() int {
 	return i.Vec3RandomAccessor.RawIndexAt(i.pos)
 }
+
+type offsetVec3dRandomAccessor struct {
+	Vec3RandomAccessor
+	origin mat.Vec3d
+}
+
+// NewOffsetVec3dRandomAccessor creates Vec3dRandomAccessor
+// which returns float32 positions of ra added to float64 origin.
+func NewOffsetVec3dRandomAccessor(ra Vec3RandomAccessor, origin mat.Vec3d) Vec3dRandomAccessor {
+	return &offsetVec3dRandomAccessor{
+		Vec3RandomAccessor: ra,
+		origin:             origin,
+	}
+}
+
+func (a *offsetVec3dRandomAccessor) Vec3dAt(i int) mat.Vec3d {
+	return a.Vec3At(i).Vec3d().Add(a.origin)
+}
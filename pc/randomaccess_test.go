@@ -29,3 +29,25 @@ func TestVec3RandomAccessorIterator(t *testing.T) {
 		t.Fatalf("Iterator must be invalid at the end")
 	}
 }
+
+func TestOffsetVec3dRandomAccessor(t *testing.T) {
+	vs := Vec3Slice{
+		{0.5, 1.0, 0.0},
+		{-0.25, 2.0, 0.125},
+	}
+	origin := mat.Vec3d{500000.01, 4000000.02, 10.03}
+	ra := NewOffsetVec3dRandomAccessor(vs, origin)
+
+	if n := ra.Len(); n != 2 {
+		t.Fatalf("Expected len: 2, got: %d", n)
+	}
+	for i := range vs {
+		expected := vs[i].Vec3d().Add(origin)
+		if v := ra.Vec3dAt(i); !v.Equal(expected) {
+			t.Errorf("%d: Expected Vec3d: %v, got: %v", i, expected, v)
+		}
+		if ri := ra.RawIndexAt(i); ri != i {
+			t.Errorf("%d: Expected RawIndex: %d, got: %d", i, i, ri)
+		}
+	}
+}
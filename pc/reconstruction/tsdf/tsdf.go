@@ -0,0 +1,214 @@
+// Package tsdf implements truncated signed distance field volume integration.
+package tsdf
+
+import (
+	"sort"
+
+	"github.com/seqsense/pcgol/mat"
+	"github.com/seqsense/pcgol/pc"
+	"github.com/seqsense/pcgol/pc/storage/voxelgrid"
+)
+
+// Number of the voxels along each edge of the block.
+const blockSize = 8
+
+const defaultMaxWeight = 10000
+
+type Option func(*Volume)
+
+// WithMaxWeight sets the maximum integration weight of the voxel.
+// Smaller value makes the volume adapt quickly to the changes.
+func WithMaxWeight(w float32) Option {
+	return func(v *Volume) {
+		v.maxWeight = w
+	}
+}
+
+// Volume is a sparse TSDF volume.
+// Voxels are allocated in the blocks of 8x8x8 voxels only around the observed surface.
+// Voxel addressing is same as voxelgrid.SparseVoxelGrid whose origin is zero.
+type Volume struct {
+	addr       *voxelgrid.SparseVoxelGrid
+	truncation float32
+	maxWeight  float32
+	blocks     map[[3]int]*block
+}
+
+type block struct {
+	dist   [blockSize * blockSize * blockSize]float32
+	weight [blockSize * blockSize * blockSize]float32
+}
+
+// New creates TSDF volume.
+// truncation is the distance from the surface to be integrated
+// and should be larger than the resolution.
+func New(resolution, truncation float32, opts ...Option) *Volume {
+	v := &Volume{
+		addr:       voxelgrid.NewSparse(resolution, mat.Vec3{}),
+		truncation: truncation,
+		maxWeight:  defaultMaxWeight,
+		blocks:     make(map[[3]int]*block),
+	}
+	for _, o := range opts {
+		o(v)
+	}
+	return v
+}
+
+func (v *Volume) Resolution() float32 {
+	return v.addr.Resolution()
+}
+
+func floorDiv(a, b int) int {
+	if a < 0 {
+		return -((-a + b - 1) / b)
+	}
+	return a / b
+}
+
+func blockAddr(pos [3]int) ([3]int, int) {
+	var b [3]int
+	var local [3]int
+	for i := range pos {
+		b[i] = floorDiv(pos[i], blockSize)
+		local[i] = pos[i] - b[i]*blockSize
+	}
+	return b, local[0] + (local[1]+local[2]*blockSize)*blockSize
+}
+
+// Integrate integrates the point cloud measured by the sensor at pose.
+// Points are in the sensor coordinate and transformed by pose into the volume coordinate.
+// Points with non-finite coordinates like invalid pixels of organized clouds are skipped.
+func (v *Volume) Integrate(pp *pc.PointCloud, pose mat.Mat4) error {
+	it, err := pp.Vec3Iterator()
+	if err != nil {
+		return err
+	}
+	origin := pose.TransformAffine(mat.Vec3{})
+	res := v.Resolution()
+	for i := 0; i < it.Len(); i++ {
+		p := it.Vec3At(i)
+		if !p.IsFinite() {
+			continue
+		}
+		pw := pose.TransformAffine(p)
+		ray := pw.Sub(origin)
+		depth := ray.Norm()
+		if depth == 0 {
+			continue
+		}
+		dir := ray.Mul(1 / depth)
+
+		begin := pw.Sub(dir.Mul(v.truncation))
+		if depth < v.truncation {
+			begin = origin
+		}
+		end := pw.Add(dir.Mul(v.truncation))
+		voxelgrid.Traverse(mat.Vec3{}, res, begin, end, func(pos [3]int) bool {
+			c := mat.Vec3{float32(pos[0]) * res, float32(pos[1]) * res, float32(pos[2]) * res}
+			sdf := depth - c.Sub(origin).Dot(dir)
+			if sdf > v.truncation {
+				sdf = v.truncation
+			}
+			if sdf < -v.truncation {
+				return true
+			}
+			v.update(pos, sdf)
+			return true
+		})
+	}
+	return nil
+}
+
+func (v *Volume) update(pos [3]int, sdf float32) {
+	bAddr, i := blockAddr(pos)
+	b, ok := v.blocks[bAddr]
+	if !ok {
+		b = &block{}
+		v.blocks[bAddr] = b
+	}
+	w := b.weight[i]
+	b.dist[i] = (b.dist[i]*w + sdf) / (w + 1)
+	if w+1 < v.maxWeight {
+		b.weight[i] = w + 1
+	} else {
+		b.weight[i] = v.maxWeight
+	}
+}
+
+// Distance returns the signed distance and the weight of the voxel at p.
+// It returns false if the voxel is not observed.
+func (v *Volume) Distance(p mat.Vec3) (dist, weight float32, ok bool) {
+	pos, ok := v.addr.PosInt(p)
+	if !ok {
+		return 0, 0, false
+	}
+	return v.distanceByPosInt(pos)
+}
+
+func (v *Volume) distanceByPosInt(pos [3]int) (dist, weight float32, ok bool) {
+	bAddr, i := blockAddr(pos)
+	b, ok := v.blocks[bAddr]
+	if !ok || b.weight[i] == 0 {
+		return 0, 0, false
+	}
+	return b.dist[i], b.weight[i], true
+}
+
+// NumBlocks returns the number of the allocated blocks.
+func (v *Volume) NumBlocks() int {
+	return len(v.blocks)
+}
+
+// SurfacePoints extracts the zero crossing points of the distance field.
+// The points are linearly interpolated between the adjacent voxel centers.
+func (v *Volume) SurfacePoints() *pc.PointCloud {
+	bAddrs := make([][3]int, 0, len(v.blocks))
+	for bAddr := range v.blocks {
+		bAddrs = append(bAddrs, bAddr)
+	}
+	// Sort to make the output deterministic.
+	sort.Sort(voxelgrid.PosSorter(bAddrs))
+
+	res := v.Resolution()
+	var points []mat.Vec3
+	for _, bAddr := range bAddrs {
+		b := v.blocks[bAddr]
+		for z := 0; z < blockSize; z++ {
+			for y := 0; y < blockSize; y++ {
+				for x := 0; x < blockSize; x++ {
+					i := x + (y+z*blockSize)*blockSize
+					if b.weight[i] == 0 {
+						continue
+					}
+					d0 := b.dist[i]
+					if d0 <= -v.truncation || v.truncation <= d0 {
+						continue
+					}
+					pos := [3]int{
+						bAddr[0]*blockSize + x,
+						bAddr[1]*blockSize + y,
+						bAddr[2]*blockSize + z,
+					}
+					c0 := mat.Vec3{float32(pos[0]) * res, float32(pos[1]) * res, float32(pos[2]) * res}
+					for axis := 0; axis < 3; axis++ {
+						pos1 := pos
+						pos1[axis]++
+						d1, _, ok := v.distanceByPosInt(pos1)
+						if !ok || d1 <= -v.truncation || v.truncation <= d1 {
+							continue
+						}
+						if (d0 < 0) == (d1 < 0) {
+							continue
+						}
+						c := c0
+						c[axis] += res * d0 / (d0 - d1)
+						points = append(points, c)
+					}
+				}
+			}
+		}
+	}
+
+	return pc.NewXYZPointCloud(points)
+}
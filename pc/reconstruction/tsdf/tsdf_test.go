@@ -0,0 +1,145 @@
+package tsdf
+
+import (
+	"math"
+	"testing"
+
+	"github.com/seqsense/pcgol/mat"
+	"github.com/seqsense/pcgol/pc"
+	"github.com/seqsense/pcgol/pc/camera"
+)
+
+func planeDepthImage(c *camera.Intrinsics, depth float32) *camera.DepthImage {
+	d := camera.NewDepthImage(c.Width, c.Height)
+	for i := range d.Depth {
+		d.Depth[i] = depth
+	}
+	// Invalid pixel
+	d.Depth[0] = 0
+	return d
+}
+
+func TestVolume_Integrate(t *testing.T) {
+	c := &camera.Intrinsics{
+		Width: 32, Height: 32,
+		Fx: 32, Fy: 32, Cx: 15.5, Cy: 15.5,
+	}
+	pp, err := c.PointCloud(planeDepthImage(c, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := map[string]struct {
+		pose   mat.Mat4
+		planeZ float32
+	}{
+		"Identity": {
+			pose:   mat.Translate(0, 0, 0),
+			planeZ: 1,
+		},
+		"Translated": {
+			pose:   mat.Translate(0.05, -0.05, 0.52),
+			planeZ: 1.52,
+		},
+	}
+	for name, tt := range testCases {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			v := New(0.05, 0.15)
+			for i := 0; i < 3; i++ {
+				if err := v.Integrate(pp, tt.pose); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if v.NumBlocks() == 0 {
+				t.Fatal("Blocks must be allocated")
+			}
+
+			center := tt.pose.TransformAffine(mat.Vec3{})
+			for _, dz := range []float32{-0.1, -0.05, 0, 0.05, 0.1} {
+				p := mat.Vec3{center[0], center[1], tt.planeZ + dz}
+				d, w, ok := v.Distance(p)
+				if !ok {
+					t.Fatalf("Distance at %v must be observed", p)
+				}
+				// Each voxel is updated by multiple rays in a frame.
+				if w < 3 {
+					t.Errorf("Expected weight >= 3, got %f", w)
+				}
+				// Voxel center is quantized by the resolution.
+				pos, _ := v.addr.PosInt(p)
+				expected := tt.planeZ - float32(pos[2])*v.Resolution()
+				if diff := d - expected; diff < -0.01 || 0.01 < diff {
+					t.Errorf("Expected distance %f at %v, got %f", expected, p, d)
+				}
+			}
+			if _, _, ok := v.Distance(mat.Vec3{center[0], center[1], tt.planeZ + 0.3}); ok {
+				t.Error("Voxel far behind the surface must not be observed")
+			}
+
+			sp := v.SurfacePoints()
+			if sp.Points == 0 {
+				t.Fatal("Surface points must be extracted")
+			}
+			it, err := sp.Vec3Iterator()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < it.Len(); i++ {
+				p := it.Vec3At(i)
+				if math.Abs(float64(p[0]-center[0])) > 0.3 || math.Abs(float64(p[1]-center[1])) > 0.3 {
+					// Distance around the edge of the plane is not accurate.
+					continue
+				}
+				if diff := p[2] - tt.planeZ; diff < -0.01 || 0.01 < diff {
+					t.Errorf("Expected z=%f, got %v", tt.planeZ, p)
+				}
+			}
+		})
+	}
+}
+
+func TestVolume_MaxWeight(t *testing.T) {
+	pp := &pc.PointCloud{
+		PointCloudHeader: pc.PointCloudHeader{
+			Version: 0.7,
+			Fields:  []string{"x", "y", "z"},
+			Size:    []int{4, 4, 4},
+			Type:    []string{"F", "F", "F"},
+			Count:   []int{1, 1, 1},
+			Width:   2,
+			Height:  1,
+		},
+		Points: 2,
+		Data:   make([]byte, 2*4*3),
+	}
+	it, err := pp.Vec3Iterator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	nan := float32(math.NaN())
+	it.SetVec3(mat.Vec3{0, 0, 1})
+	it.Incr()
+	it.SetVec3(mat.Vec3{nan, nan, nan})
+
+	v := New(0.1, 0.3, WithMaxWeight(2))
+	for i := 0; i < 5; i++ {
+		if err := v.Integrate(pp, mat.Translate(0, 0, 0)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, w, ok := v.Distance(mat.Vec3{0, 0, 1})
+	if !ok {
+		t.Fatal("Voxel on the surface must be observed")
+	}
+	if w != 2 {
+		t.Errorf("Expected weight 2, got %f", w)
+	}
+}
+
+func TestVolume_SurfacePoints_empty(t *testing.T) {
+	sp := New(0.1, 0.3).SurfacePoints()
+	if sp.Points != 0 {
+		t.Errorf("Expected no points, got %d", sp.Points)
+	}
+}
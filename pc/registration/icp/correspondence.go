@@ -17,15 +17,17 @@ type PointToPointCorresponder interface {
 
 type NearestPointCorresponder struct {
 	MaxDist float32
+	// Number of goroutines to search the pairs.
+	// Base storage must be safe for concurrent use if Workers is larger than 1.
+	Workers int
 }
 
 func (c *NearestPointCorresponder) Pairs(base storage.Search, target pc.Vec3RandomAccessor) []PointToPointCorrespondence {
 	n := target.Len()
 	out := make([]PointToPointCorrespondence, 0, n)
-	for i := 0; i < n; i++ {
-		nn := base.Nearest(target.Vec3At(i), c.MaxDist)
+	appendPair := func(i int, nn storage.Neighbor) {
 		if nn.ID < 0 {
-			continue
+			return
 		}
 		out = append(out, PointToPointCorrespondence{
 			BaseID:          nn.ID,
@@ -33,5 +35,15 @@ func (c *NearestPointCorresponder) Pairs(base storage.Search, target pc.Vec3Rand
 			SquaredDistance: nn.DistSq,
 		})
 	}
+	if c.Workers < 2 {
+		for i := 0; i < n; i++ {
+			appendPair(i, base.Nearest(target.Vec3At(i), c.MaxDist))
+		}
+		return out
+	}
+	nns := storage.BatchSearch{Search: base, Workers: c.Workers}.NearestAll(target, c.MaxDist)
+	for i, nn := range nns {
+		appendPair(i, nn)
+	}
 	return out
 }
@@ -35,3 +35,21 @@ func TestNearestPointCorresponder(t *testing.T) {
 		t.Errorf("Expected pairs: %v, got: %v", expected, pairs)
 	}
 }
+
+func TestNearestPointCorresponder_workers(t *testing.T) {
+	base := make(pc.Vec3Slice, 1000)
+	for i := range base {
+		base[i] = mat.Vec3{float32(i % 10), float32(i / 10 % 10), float32(i / 100)}
+	}
+	targets := make(pc.Vec3Slice, 1000)
+	for i := range targets {
+		targets[i] = base[i].Add(mat.Vec3{0.1, 0.2, float32(i%3) * 0.3})
+	}
+	kdt := kdtree.New(base)
+
+	expected := (&NearestPointCorresponder{MaxDist: 0.5}).Pairs(kdt, targets)
+	pairs := (&NearestPointCorresponder{MaxDist: 0.5, Workers: 4}).Pairs(kdt, targets)
+	if !reflect.DeepEqual(expected, pairs) {
+		t.Errorf("Expected pairs: %v, got: %v", expected, pairs)
+	}
+}
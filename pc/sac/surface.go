@@ -7,7 +7,7 @@ import (
 )
 
 type voxelGridSurfaceModel struct {
-	vg                   *voxelgrid.VoxelGrid
+	vg                   voxelgrid.Grid
 	ra                   pc.Vec3RandomAccessor
 	vgMin, vgMax, vgSize mat.Vec3
 }
@@ -17,7 +17,7 @@ const (
 	epsilon = 0.01
 )
 
-func NewVoxelGridSurfaceModel(vg *voxelgrid.VoxelGrid, ra pc.Vec3RandomAccessor) *voxelGridSurfaceModel {
+func NewVoxelGridSurfaceModel(vg voxelgrid.Grid, ra pc.Vec3RandomAccessor) *voxelGridSurfaceModel {
 	vgMin, vgMax := vg.MinMax()
 	vgSize := vgMax.Sub(vgMin)
 	return &voxelGridSurfaceModel{
@@ -200,19 +200,19 @@ type voxelGridSurfaceModelCoefficients struct {
 }
 
 func (c *voxelGridSurfaceModelCoefficients) Evaluate() int {
-	added := make([]bool, c.model.vg.Len())
+	added := make(map[[3]int]bool)
 	var cnt int
 
 	for a := float32(0); a <= 1; a += c.l1 {
 		for b := float32(0); b <= 1; b += c.l2 {
 			p := c.origin.Add(c.v1.Mul(a)).Add(c.v2.Mul(b))
-			addr, ok := c.model.vg.Addr(p)
+			pos, ok := c.model.vg.PosInt(p)
 			if !ok {
 				continue
 			}
-			if !added[addr] {
-				added[addr] = true
-				cnt += len(c.model.vg.GetByAddr(addr))
+			if !added[pos] {
+				added[pos] = true
+				cnt += len(c.model.vg.GetByPosInt(pos))
 			}
 		}
 	}
@@ -104,3 +104,43 @@ func TestVoxelGridSurfaceModel(t *testing.T) {
 		})
 	}
 }
+
+func TestVoxelGridSurfaceModel_sparse(t *testing.T) {
+	pp := pc.Vec3Slice{
+		mat.Vec3{0.0, 0.0, 0.0},
+		mat.Vec3{0.1, 0.0, 0.1},
+		mat.Vec3{0.2, 0.0, 0.2},
+		mat.Vec3{0.2, 0.1, 0.6}, // outlier
+		mat.Vec3{0.0, 0.1, 0.0},
+		mat.Vec3{0.1, 0.1, 0.1},
+		mat.Vec3{0.2, 0.1, 0.2},
+		mat.Vec3{0.0, 0.2, 0.0},
+		mat.Vec3{0.1, 0.2, 0.1},
+		mat.Vec3{0.2, 0.2, 0.2},
+	}
+	vg := voxelgrid.New(0.1, [3]int{8, 8, 8}, mat.Vec3{})
+	svg := voxelgrid.NewSparse(0.1, mat.Vec3{})
+	for i, p := range pp {
+		vg.Add(p, i)
+		svg.Add(p, i)
+	}
+
+	c, ok := NewVoxelGridSurfaceModel(vg, pp).Fit([]int{1, 5, 7})
+	if !ok {
+		t.Fatal("Fit failed")
+	}
+	cs, ok := NewVoxelGridSurfaceModel(svg, pp).Fit([]int{1, 5, 7})
+	if !ok {
+		t.Fatal("Fit failed")
+	}
+	if n, ns := c.Evaluate(), cs.Evaluate(); n != ns {
+		t.Errorf("Expected evaluation result: %d, got: %d", n, ns)
+	}
+
+	indice := cs.Inliers(0.1)
+	sort.Ints(indice)
+	expectedIndice := []int{0, 1, 2, 4, 5, 6, 7, 8, 9}
+	if !reflect.DeepEqual(expectedIndice, indice) {
+		t.Errorf("Expected inlier: %v, got: %v", expectedIndice, indice)
+	}
+}
@@ -38,7 +38,33 @@ func (v *VoxelGrid) Storage() *storage.VoxelGrid {
 
 func (v *VoxelGrid) Segment(p mat.Vec3) []int {
 	searched := make([]bool, v.Len())
-	pos, ok := v.PosInt(p)
+	return segment(v, p, func(pos [3]int) bool {
+		addr, ok := v.AddrByPosInt(pos)
+		if !ok || searched[addr] {
+			return false
+		}
+		searched[addr] = true
+		return true
+	})
+}
+
+// Segment returns indice of the points in the voxels connected to the voxel at p.
+// It can be used with any storage.Grid like storage.SparseVoxelGrid.
+func Segment(g storage.Grid, p mat.Vec3) []int {
+	searched := make(map[[3]int]bool)
+	return segment(g, p, func(pos [3]int) bool {
+		if searched[pos] {
+			return false
+		}
+		searched[pos] = true
+		return true
+	})
+}
+
+// segment searches connected voxels.
+// visit must return false if the voxel is already searched or out of the grid.
+func segment(g storage.Grid, p mat.Vec3, visit func([3]int) bool) []int {
+	pos, ok := g.PosInt(p)
 	if !ok {
 		return nil
 	}
@@ -49,24 +75,17 @@ func (v *VoxelGrid) Segment(p mat.Vec3) []int {
 	for len(next) > 0 {
 		var pos [3]int
 		pos, next = next[0], next[1:]
-		addr, ok := v.AddrByPosInt(pos)
-		if !ok || searched[addr] {
+		if !visit(pos) {
 			continue
 		}
-		searched[addr] = true
-		c := v.GetByAddr(addr)
+		c := g.GetByPosInt(pos)
 		if len(c) == 0 {
 			continue
 		}
 		indice = append(indice, c...)
 
 		for _, d := range cursor {
-			n := [3]int{pos[0] + d[0], pos[1] + d[1], pos[2] + d[2]}
-			addr, ok := v.AddrByPosInt(n)
-			if !ok || searched[addr] {
-				continue
-			}
-			next = append(next, n)
+			next = append(next, [3]int{pos[0] + d[0], pos[1] + d[1], pos[2] + d[2]})
 		}
 	}
 	return indice
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/seqsense/pcgol/mat"
+	storage "github.com/seqsense/pcgol/pc/storage/voxelgrid"
 )
 
 func TestVoxelGrid_GetSegment(t *testing.T) {
@@ -38,3 +39,50 @@ func TestVoxelGrid_GetSegment(t *testing.T) {
 		t.Errorf("Expected indice:\n%v\ngot:\n%v", expected, indice)
 	}
 }
+
+func TestSegment_sparse(t *testing.T) {
+	pp := []mat.Vec3{
+		{0.00, 0.00, 0.00},  // 0
+		{0.05, 0.05, 0.00},  // 1
+		{0.10, 0.10, 0.05},  // 2
+		{0.30, 0.00, 0.00},  // 3
+		{-5.00, 0.00, 0.00}, // 4
+		{-5.05, 0.00, 0.00}, // 5
+	}
+
+	v := storage.NewSparse(0.05, mat.Vec3{})
+	for i, p := range pp {
+		v.Add(p, i)
+	}
+	testCases := map[string]struct {
+		p        mat.Vec3
+		expected []int
+	}{
+		"Origin": {
+			p:        mat.Vec3{},
+			expected: []int{0, 1, 2},
+		},
+		"Isolated": {
+			p:        mat.Vec3{0.3, 0, 0},
+			expected: []int{3},
+		},
+		"Negative": {
+			p:        mat.Vec3{-5, 0, 0},
+			expected: []int{4, 5},
+		},
+		"Empty": {
+			p:        mat.Vec3{1, 1, 1},
+			expected: []int{},
+		},
+	}
+	for name, tt := range testCases {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			indice := Segment(v, tt.p)
+			sort.Ints(indice)
+			if !reflect.DeepEqual(tt.expected, indice) {
+				t.Errorf("Expected indice:\n%v\ngot:\n%v", tt.expected, indice)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/seqsense/pcgol/mat"
+	"github.com/seqsense/pcgol/pc"
+)
+
+// Number of queries processed at once by a worker.
+const batchBlockSize = 64
+
+// BatchSearch runs the queries of all target points across the workers.
+// Results are returned in the order of the target points.
+// Search must be safe for concurrent use if Workers is larger than 1.
+type BatchSearch struct {
+	Search
+	// Number of goroutines. Queries are processed sequentially if Workers < 2.
+	Workers int
+}
+
+func (b BatchSearch) NearestAll(targets pc.Vec3RandomAccessor, maxRange float32) []Neighbor {
+	out := make([]Neighbor, targets.Len())
+	b.run(targets.Len(), func(i int) {
+		out[i] = b.Nearest(targets.Vec3At(i), maxRange)
+	})
+	return out
+}
+
+func (b BatchSearch) RangeAll(targets pc.Vec3RandomAccessor, maxRange float32) [][]Neighbor {
+	out := make([][]Neighbor, targets.Len())
+	b.run(targets.Len(), func(i int) {
+		out[i] = b.Range(targets.Vec3At(i), maxRange)
+	})
+	return out
+}
+
+// RangeCountAll returns the number of the neighbors in maxRange of each target point.
+// Unlike RangeAll, the neighbor lists are not kept.
+func (b BatchSearch) RangeCountAll(targets pc.Vec3RandomAccessor, maxRange float32) []int {
+	out := make([]int, targets.Len())
+	b.run(targets.Len(), func(i int) {
+		out[i] = len(b.Range(targets.Vec3At(i), maxRange))
+	})
+	return out
+}
+
+// KNearestAll runs k-nearest neighbor search.
+// If Search doesn't implement KSearch, neighbors are taken from the result of Range.
+func (b BatchSearch) KNearestAll(targets pc.Vec3RandomAccessor, k int, maxRange float32) [][]Neighbor {
+	out := make([][]Neighbor, targets.Len())
+	knn, ok := b.Search.(KSearch)
+	if !ok {
+		knn = rangeKSearch{b.Search}
+	}
+	b.run(targets.Len(), func(i int) {
+		out[i] = knn.KNearest(targets.Vec3At(i), k, maxRange)
+	})
+	return out
+}
+
+func (b BatchSearch) run(n int, fn func(i int)) {
+	if b.Workers < 2 || n <= batchBlockSize {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+
+	var next int64
+	var wg sync.WaitGroup
+	wg.Add(b.Workers)
+	for w := 0; w < b.Workers; w++ {
+		go func() {
+			defer wg.Done()
+			for {
+				begin := int(atomic.AddInt64(&next, batchBlockSize)) - batchBlockSize
+				if begin >= n {
+					return
+				}
+				end := begin + batchBlockSize
+				if end > n {
+					end = n
+				}
+				for i := begin; i < end; i++ {
+					fn(i)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+type rangeKSearch struct {
+	Search
+}
+
+func (s rangeKSearch) KNearest(p mat.Vec3, k int, maxRange float32) []Neighbor {
+	if k <= 0 {
+		return nil
+	}
+	neighbors := s.Range(p, maxRange)
+	if len(neighbors) > k {
+		neighbors = neighbors[:k]
+	}
+	return neighbors
+}
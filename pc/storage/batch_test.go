@@ -0,0 +1,101 @@
+package storage_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/seqsense/pcgol/pc/internal/pctest"
+	"github.com/seqsense/pcgol/pc/storage"
+	"github.com/seqsense/pcgol/pc/storage/kdtree"
+)
+
+// rangeOnlySearch hides KNearest of the base storage.
+type rangeOnlySearch struct {
+	storage.Search
+}
+
+func TestBatchSearch(t *testing.T) {
+	const (
+		width    = 10.0
+		maxRange = 1.0
+	)
+	kdt := kdtree.New(pctest.RandomCloud(1000, 0, width))
+	targets := pctest.RandomCloud(1000, 0, width)
+
+	expectedNearest := make([]storage.Neighbor, targets.Len())
+	expectedRange := make([][]storage.Neighbor, targets.Len())
+	expectedRangeCount := make([]int, targets.Len())
+	expectedKNearest := make([][]storage.Neighbor, targets.Len())
+	for i, p := range targets {
+		expectedNearest[i] = kdt.Nearest(p, maxRange)
+		expectedRange[i] = kdt.Range(p, maxRange)
+		expectedRangeCount[i] = len(expectedRange[i])
+		expectedKNearest[i] = kdt.KNearest(p, 3, maxRange)
+	}
+
+	for _, workers := range []int{0, 1, 4} {
+		workers := workers
+		t.Run(fmt.Sprintf("Workers=%d", workers), func(t *testing.T) {
+			b := storage.BatchSearch{Search: kdt, Workers: workers}
+			if nn := b.NearestAll(targets, maxRange); !reflect.DeepEqual(expectedNearest, nn) {
+				t.Error("NearestAll result differs")
+			}
+			if nn := b.RangeAll(targets, maxRange); !reflect.DeepEqual(expectedRange, nn) {
+				t.Error("RangeAll result differs")
+			}
+			if nn := b.RangeCountAll(targets, maxRange); !reflect.DeepEqual(expectedRangeCount, nn) {
+				t.Error("RangeCountAll result differs")
+			}
+			if nn := b.KNearestAll(targets, 3, maxRange); !reflect.DeepEqual(expectedKNearest, nn) {
+				t.Error("KNearestAll result differs")
+			}
+
+			b2 := storage.BatchSearch{Search: rangeOnlySearch{kdt}, Workers: workers}
+			nn := b2.KNearestAll(targets, 3, maxRange)
+			for i := range nn {
+				if len(nn[i]) != len(expectedKNearest[i]) {
+					t.Fatalf("Expected %v, got %v", expectedKNearest[i], nn[i])
+				}
+				for j := range nn[i] {
+					if nn[i][j].DistSq != expectedKNearest[i][j].DistSq {
+						t.Fatalf("Expected %v, got %v", expectedKNearest[i], nn[i])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestBatchSearch_KNearestAll_nonPositiveK(t *testing.T) {
+	const width = 10.0
+	kdt := kdtree.New(pctest.RandomCloud(100, 0, width))
+	targets := pctest.RandomCloud(10, 0, width)
+
+	for _, k := range []int{0, -1} {
+		k := k
+		t.Run(fmt.Sprintf("K=%d", k), func(t *testing.T) {
+			b := storage.BatchSearch{Search: rangeOnlySearch{kdt}}
+			for i, nn := range b.KNearestAll(targets, k, width) {
+				if len(nn) != 0 {
+					t.Errorf("Expected no neighbors for target %d, got %v", i, nn)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkBatchSearch_NearestAll(b *testing.B) {
+	const width = 10.0
+	kdt := kdtree.New(pctest.RandomCloud(100000, 0, width))
+	targets := pctest.RandomCloud(10000, 0, width)
+
+	for _, workers := range []int{1, 4} {
+		bs := storage.BatchSearch{Search: kdt, Workers: workers}
+		b.Run(fmt.Sprintf("Workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_ = bs.NearestAll(targets, 1)
+			}
+		})
+	}
+}
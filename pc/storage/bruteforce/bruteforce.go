@@ -0,0 +1,67 @@
+// Package bruteforce provides reference implementation of the spatial storages
+// by linear scan.
+package bruteforce
+
+import (
+	"sort"
+
+	"github.com/seqsense/pcgol/mat"
+	"github.com/seqsense/pcgol/pc"
+	"github.com/seqsense/pcgol/pc/storage"
+)
+
+// Search implements storage.Search, storage.KSearch and storage.VolumeSearch
+// by scanning all points.
+type Search struct {
+	pc.Vec3RandomAccessor
+}
+
+func New(ra pc.Vec3RandomAccessor) *Search {
+	return &Search{Vec3RandomAccessor: ra}
+}
+
+func (s *Search) Nearest(p mat.Vec3, maxRange float32) storage.Neighbor {
+	nn := storage.Neighbor{ID: -1, DistSq: maxRange * maxRange}
+	for i := 0; i < s.Len(); i++ {
+		if dsq := s.Vec3At(i).Sub(p).NormSq(); dsq < nn.DistSq {
+			nn.ID, nn.DistSq = i, dsq
+		}
+	}
+	return nn
+}
+
+func (s *Search) Range(p mat.Vec3, maxRange float32) []storage.Neighbor {
+	maxRangeSq := maxRange * maxRange
+	neighbors := []storage.Neighbor{}
+	for i := 0; i < s.Len(); i++ {
+		if dsq := s.Vec3At(i).Sub(p).NormSq(); dsq < maxRangeSq {
+			neighbors = append(neighbors, storage.Neighbor{ID: i, DistSq: dsq})
+		}
+	}
+	// Stable sort to make the order of the same distance points deterministic.
+	sort.SliceStable(neighbors, func(i, j int) bool {
+		return neighbors[i].DistSq < neighbors[j].DistSq
+	})
+	return neighbors
+}
+
+func (s *Search) KNearest(p mat.Vec3, k int, maxRange float32) []storage.Neighbor {
+	if k <= 0 {
+		return []storage.Neighbor{}
+	}
+	neighbors := s.Range(p, maxRange)
+	if len(neighbors) > k {
+		neighbors = neighbors[:k]
+	}
+	return neighbors
+}
+
+func (s *Search) Inside(v storage.Volume) []int {
+	out := []int{}
+	for i := 0; i < s.Len(); i++ {
+		if v.Contains(s.Vec3At(i)) {
+			out = append(out, i)
+		}
+	}
+	return out
+}
@@ -0,0 +1,21 @@
+package bruteforce_test
+
+import (
+	"testing"
+
+	"github.com/seqsense/pcgol/pc"
+	"github.com/seqsense/pcgol/pc/storage"
+	"github.com/seqsense/pcgol/pc/storage/bruteforce"
+	"github.com/seqsense/pcgol/pc/storage/storagetest"
+)
+
+var (
+	_ storage.KSearch      = &bruteforce.Search{}
+	_ storage.VolumeSearch = &bruteforce.Search{}
+)
+
+func TestSearch(t *testing.T) {
+	storagetest.TestSearch(t, func(t *testing.T, ra pc.Vec3RandomAccessor) storage.Search {
+		return bruteforce.New(ra)
+	})
+}
@@ -0,0 +1,149 @@
+package kdtree
+
+import (
+	"sync"
+
+	"github.com/seqsense/pcgol/pc"
+)
+
+// Minimum number of the points to build the subtree on another goroutine.
+const minParallelBuildSize = 1 << 12
+
+// WithWorkers sets the number of goroutines used to build the tree.
+func WithWorkers(n int) KDTreeOption {
+	return func(k *KDTree) {
+		k.workers = n
+	}
+}
+
+type treeBuilder struct {
+	nodes []node
+	slots []int32
+	sem   chan struct{}
+	wg    sync.WaitGroup
+}
+
+// buildTree builds balanced tree of the given points and returns the root index.
+// Nodes are stored to the slots of nodes in in-order layout.
+// If slots is nil, nodes[:len(indice)] are used.
+func buildTree(ra pc.Vec3RandomAccessor, indice []int, nodes []node, slots []int32, dim int, workers int) int32 {
+	if len(indice) == 0 {
+		return nilNode
+	}
+	// Accessing points through Vec3RandomAccessor interface is slow.
+	// Copy the points to the slice in the same order as indice before the selection.
+	pts := make(pc.Vec3Slice, len(indice))
+	for i, id := range indice {
+		pts[i] = ra.Vec3At(id)
+	}
+	b := &treeBuilder{
+		nodes: nodes,
+		slots: slots,
+	}
+	if workers > 1 {
+		b.sem = make(chan struct{}, workers-1)
+	}
+	root := b.build(pts, indice, 0, dim)
+	b.wg.Wait()
+	return root
+}
+
+func (b *treeBuilder) slot(i int) int32 {
+	if b.slots == nil {
+		return int32(i)
+	}
+	return b.slots[i]
+}
+
+func (b *treeBuilder) build(pts pc.Vec3Slice, indice []int, offset int, dim int) int32 {
+	mid := len(indice) / 2
+	selectNth(pts, indice, mid, dim)
+
+	i := b.slot(offset + mid)
+	n := &b.nodes[i]
+	n.id = indice[mid]
+	n.dim = dim
+	n.children = [2]int32{nilNode, nilNode}
+
+	next := (dim + 1) % 3
+	if mid > 0 {
+		if !b.buildAsync(&n.children[0], pts[:mid], indice[:mid], offset, next) {
+			n.children[0] = b.build(pts[:mid], indice[:mid], offset, next)
+		}
+	}
+	if mid+1 < len(indice) {
+		n.children[1] = b.build(pts[mid+1:], indice[mid+1:], offset+mid+1, next)
+	}
+	return i
+}
+
+func (b *treeBuilder) buildAsync(out *int32, pts pc.Vec3Slice, indice []int, offset int, dim int) bool {
+	if b.sem == nil || len(indice) < minParallelBuildSize {
+		return false
+	}
+	select {
+	case b.sem <- struct{}{}:
+	default:
+		return false
+	}
+	b.wg.Add(1)
+	go func() {
+		*out = b.build(pts, indice, offset, dim)
+		<-b.sem
+		b.wg.Done()
+	}()
+	return true
+}
+
+// selectNth partially sorts indice so that the nth element is placed at the
+// position in sorted order by the dim coordinate, like std::nth_element.
+// pts[i] must be the point of indice[i] and is reordered together.
+// Elements before nth are less than or equal to the nth element and elements
+// after nth are greater than or equal to the nth element.
+func selectNth(pts pc.Vec3Slice, indice []int, nth int, dim int) {
+	val := func(i int) float32 {
+		return pts[i][dim]
+	}
+	swap := func(i, j int) {
+		pts[i], pts[j] = pts[j], pts[i]
+		indice[i], indice[j] = indice[j], indice[i]
+	}
+	lo, hi := 0, len(indice)-1
+	for lo < hi {
+		// Median of three
+		mid := lo + (hi-lo)/2
+		if val(mid) < val(lo) {
+			swap(mid, lo)
+		}
+		if val(hi) < val(lo) {
+			swap(hi, lo)
+		}
+		if val(hi) < val(mid) {
+			swap(hi, mid)
+		}
+		pivot := val(mid)
+
+		i, j := lo, hi
+		for i <= j {
+			for val(i) < pivot {
+				i++
+			}
+			for val(j) > pivot {
+				j--
+			}
+			if i <= j {
+				swap(i, j)
+				i++
+				j--
+			}
+		}
+		switch {
+		case nth <= j:
+			hi = j
+		case nth >= i:
+			lo = i
+		default:
+			return
+		}
+	}
+}
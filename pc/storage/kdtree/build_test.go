@@ -0,0 +1,90 @@
+package kdtree
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/seqsense/pcgol/mat"
+	"github.com/seqsense/pcgol/pc"
+)
+
+func TestSelectNth(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 10, 101} {
+		pts := make(pc.Vec3Slice, n)
+		for i := range pts {
+			pts[i] = randomPoint(10)
+		}
+		for nth := 0; nth < n; nth++ {
+			indice := make([]int, n)
+			for i := range indice {
+				indice[i] = i
+			}
+			sorted := append(pc.Vec3Slice{}, pts...)
+			selectNth(sorted, indice, nth, 1)
+			pivot := sorted[nth][1]
+			for i, id := range indice {
+				if sorted[i] != pts[id] {
+					t.Fatalf("%d points, nth=%d: point %d is not moved with its index", n, nth, i)
+				}
+				v := sorted[i][1]
+				if (i < nth && v > pivot) || (i > nth && v < pivot) {
+					t.Fatalf("%d points, nth=%d: wrong order at %d", n, nth, i)
+				}
+			}
+		}
+	}
+}
+
+func TestSelectNth_duplicated(t *testing.T) {
+	ra := make(pc.Vec3Slice, 100)
+	for i := range ra {
+		ra[i] = mat.Vec3{float32(i % 3), 0, 0}
+	}
+	indice := make([]int, len(ra))
+	for i := range indice {
+		indice[i] = i
+	}
+	selectNth(ra, indice, 50, 0)
+	if v := ra[50][0]; v != 1 {
+		t.Errorf("Expected median: 1, got: %f", v)
+	}
+}
+
+func TestNew_workers(t *testing.T) {
+	const nPoints = 20000
+
+	pp := generateRandomCloud(t, nPoints, 10)
+	it, err := pp.Vec3Iterator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := New(it)
+	for _, workers := range []int{2, 4, 8} {
+		workers := workers
+		t.Run(fmt.Sprintf("Workers=%d", workers), func(t *testing.T) {
+			kdt := New(it, WithWorkers(workers))
+			if expected.root != kdt.root || !reflect.DeepEqual(expected.nodes, kdt.nodes) {
+				t.Error("Tree built in parallel differs")
+			}
+		})
+	}
+}
+
+func BenchmarkNew(b *testing.B) {
+	for _, nPoints := range []int{10000, 1000000} {
+		pp := generateRandomCloud(b, nPoints, 10)
+		it, err := pp.Vec3Iterator()
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, workers := range []int{1, 4} {
+			workers := workers
+			b.Run(fmt.Sprintf("%dpoints/Workers=%d", nPoints, workers), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					_ = New(it, WithWorkers(workers))
+				}
+			})
+		}
+	}
+}
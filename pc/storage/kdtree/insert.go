@@ -0,0 +1,108 @@
+package kdtree
+
+import (
+	"fmt"
+	"math"
+)
+
+// Balance factor of the tree.
+// Subtree is rebuilt if the size of its child exceeds alpha * (size of the subtree).
+const alpha = 0.75
+
+var logAlphaInv = math.Log(1 / alpha)
+
+// Insert adds the point to the tree.
+// The point must be already stored in the base Vec3RandomAccessor and must not be in the tree.
+// If points are appended to the base storage after building the tree,
+// KDTree.Vec3RandomAccessor should be updated before calling Insert.
+//
+// Tree is partially rebuilt like scapegoat tree to keep its depth bounded.
+func (k *KDTree) Insert(pID int) error {
+	if pID < 0 || pID > k.Len()-1 {
+		return fmt.Errorf("%d does not correspond to any point in the storage", pID)
+	}
+	k.size++
+	if k.root == nilNode {
+		k.root = k.newNode(pID, 0)
+		return nil
+	}
+
+	p := k.Vec3At(pID)
+	path := make([]int32, 0, 32)
+	i := k.root
+	for {
+		path = append(path, i)
+		n := &k.nodes[i]
+		c := 1
+		if k.Vec3At(n.id)[n.dim] > p[n.dim] {
+			c = 0
+		}
+		if n.children[c] == nilNode {
+			// newNode may reallocate the node array.
+			child := k.newNode(pID, (n.dim+1)%3)
+			k.nodes[i].children[c] = child
+			path = append(path, child)
+			break
+		}
+		i = n.children[c]
+	}
+
+	if float64(len(path)) <= math.Log(float64(k.size))/logAlphaInv {
+		return nil
+	}
+
+	// Find scapegoat node from the bottom of the path.
+	size := 1
+	for j := len(path) - 2; j >= 0; j-- {
+		n := &k.nodes[path[j]]
+		sizeNode := 1 + k.subtreeSize(n.children[0]) + k.subtreeSize(n.children[1])
+		if float64(size) > alpha*float64(sizeNode) {
+			ids := make([]int, 0, sizeNode)
+			slots := make([]int32, 0, sizeNode)
+			k.collectSubtree(path[j], &ids, &slots)
+			// Rebuilt subtree reuses the nodes of the original subtree.
+			rebuilt := buildTree(k.Vec3RandomAccessor, ids, k.nodes, slots, n.dim, 1)
+			switch {
+			case j == 0:
+				k.root = rebuilt
+			case k.nodes[path[j-1]].children[0] == path[j]:
+				k.nodes[path[j-1]].children[0] = rebuilt
+			default:
+				k.nodes[path[j-1]].children[1] = rebuilt
+			}
+			return nil
+		}
+		size = sizeNode
+	}
+	return nil
+}
+
+// newNode allocates a leaf node, reusing the node released by DeletePoint if available.
+func (k *KDTree) newNode(pID int, dim int) int32 {
+	n := node{children: [2]int32{nilNode, nilNode}, id: pID, dim: dim}
+	if l := len(k.free); l > 0 {
+		i := k.free[l-1]
+		k.free = k.free[:l-1]
+		k.nodes[i] = n
+		return i
+	}
+	k.nodes = append(k.nodes, n)
+	return int32(len(k.nodes) - 1)
+}
+
+func (k *KDTree) subtreeSize(i int32) int {
+	if i == nilNode {
+		return 0
+	}
+	return 1 + k.subtreeSize(k.nodes[i].children[0]) + k.subtreeSize(k.nodes[i].children[1])
+}
+
+func (k *KDTree) collectSubtree(i int32, ids *[]int, slots *[]int32) {
+	if i == nilNode {
+		return
+	}
+	*ids = append(*ids, k.nodes[i].id)
+	*slots = append(*slots, i)
+	k.collectSubtree(k.nodes[i].children[0], ids, slots)
+	k.collectSubtree(k.nodes[i].children[1], ids, slots)
+}
@@ -0,0 +1,117 @@
+package kdtree
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/seqsense/pcgol/mat"
+	"github.com/seqsense/pcgol/pc"
+)
+
+func TestKDtree_Insert_randomCloud(t *testing.T) {
+	const (
+		nPoints = 100
+		width   = 10.0
+	)
+
+	pp := generateRandomCloud(t, nPoints, width)
+	it, err := pp.Vec3Iterator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var all pc.Vec3Slice
+	for i := 0; i < it.Len(); i++ {
+		all = append(all, it.Vec3At(i))
+	}
+
+	kdt := New(all[:nPoints/2])
+	kdt.Vec3RandomAccessor = all
+	for i := nPoints / 2; i < nPoints; i++ {
+		if err := kdt.Insert(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ns := newNaiveSearch(all)
+	testNearestRandomCloud(t, all, kdt, ns, nPoints, width)
+
+	for i := 0; i < nPoints; i++ {
+		p := randomPoint(width)
+		maxRange := rand.Float32() * width
+
+		neighborsNaive := ns.Range(p, maxRange)
+		neighborsKDTree := kdt.Range(p, maxRange)
+		sort.Sort(neighborIDSorter(neighborsNaive))
+		sort.Sort(neighborIDSorter(neighborsKDTree))
+
+		if !reflect.DeepEqual(neighborsNaive, neighborsKDTree) {
+			t.Fatalf("Expected: %v, got %v", neighborsNaive, neighborsKDTree)
+		}
+	}
+
+	if err := kdt.Insert(nPoints); err == nil {
+		t.Error("Expected error on inserting out of range ID")
+	}
+}
+
+func TestKDtree_Insert_balance(t *testing.T) {
+	const nPoints = 1000
+
+	// Sequentially inserting sorted points makes unbalanced tree
+	// without rebalancing.
+	var all pc.Vec3Slice
+	for i := 0; i < nPoints; i++ {
+		all = append(all, mat.Vec3{float32(i), float32(i), float32(i)})
+	}
+	kdt := New(pc.Vec3Slice{})
+	kdt.Vec3RandomAccessor = all
+	for i := 0; i < nPoints; i++ {
+		if err := kdt.Insert(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	maxDepth := kdt.maxDepth(kdt.root, 0)
+	limit := int(math.Log(nPoints)/logAlphaInv) + 1
+	if maxDepth > limit {
+		t.Errorf("Expected max depth <= %d, got %d", limit, maxDepth)
+	}
+
+	for i := 0; i < nPoints; i++ {
+		n := kdt.Nearest(all[i], 0.5)
+		if n.ID != i {
+			t.Fatalf("Expected nearest ID: %d, got %d", i, n.ID)
+		}
+	}
+}
+
+func TestKDtree_Insert_afterDelete(t *testing.T) {
+	const (
+		nPoints = 100
+		width   = 10.0
+	)
+
+	pp := generateRandomCloud(t, nPoints, width)
+	it, err := pp.Vec3Iterator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	kdt := New(it)
+	nNodes := len(kdt.nodes)
+	for i := 0; i < nPoints; i += 2 {
+		if err := kdt.DeletePoint(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < nPoints; i += 2 {
+		if err := kdt.Insert(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(kdt.nodes) != nNodes {
+		t.Errorf("Expected released nodes to be reused: %d nodes, got %d", nNodes, len(kdt.nodes))
+	}
+	testNearestRandomCloud(t, it, kdt, newNaiveSearch(it), nPoints, width)
+}
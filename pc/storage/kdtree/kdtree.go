@@ -1,6 +1,7 @@
 package kdtree
 
 import (
+	"container/heap"
 	"fmt"
 	"sort"
 	"strings"
@@ -13,17 +14,33 @@ import (
 
 type KDTree struct {
 	pc.Vec3RandomAccessor
-	root *node
+
+	// Nodes are stored in a flat array and linked by the index
+	// to reduce allocations and improve memory locality.
+	nodes []node
+	root  int32
+
+	// Index of the nodes released by DeletePoint to be reused by Insert.
+	free []int32
 
 	poolNodeStack *sync.Pool
 
+	// Number of the nodes in the tree.
+	size int
+
+	// Number of goroutines to build the tree.
+	workers int
+
 	// Squared distance to cut the search branch.
 	// If this value is larger than zero, Nearest will be approximated search.
 	MinDistSq float32
 }
 
+// nilNode is the index representing absence of the node.
+const nilNode int32 = -1
+
 type node struct {
-	children [2]*node
+	children [2]int32
 	id       int
 	dim      int
 }
@@ -31,30 +48,36 @@ type node struct {
 type KDTreeOption func(*KDTree)
 
 func New(ra pc.Vec3RandomAccessor, opts ...KDTreeOption) *KDTree {
-	ids := make([]int, ra.Len())
-	for i := 0; i < ra.Len(); i++ {
-		ids[i] = i
-	}
-	root := newNode(ra, ids, 0)
-	maxDepth := root.maxDepth(0)
 	kdt := &KDTree{
 		Vec3RandomAccessor: ra,
-		root:               root,
-
-		poolNodeStack: &sync.Pool{
-			New: func() interface{} {
-				return &nodeStack{
-					nn: make([]*node, 0, maxDepth),
-				}
-			},
-		},
+		workers:            1,
 	}
 	for _, o := range opts {
 		o(kdt)
 	}
+
+	ids := make([]int, ra.Len())
+	for i := 0; i < ra.Len(); i++ {
+		ids[i] = i
+	}
+	kdt.nodes = make([]node, len(ids))
+	kdt.root = buildTree(ra, ids, kdt.nodes, nil, 0, kdt.workers)
+	kdt.size = len(ids)
+	kdt.initPool()
 	return kdt
 }
 
+func (k *KDTree) initPool() {
+	maxDepth := k.maxDepth(k.root, 0)
+	k.poolNodeStack = &sync.Pool{
+		New: func() interface{} {
+			return &nodeStack{
+				nn: make([]int32, 0, maxDepth),
+			}
+		},
+	}
+}
+
 // With creates shallow copy of KDTree with specified options.
 func (k *KDTree) With(opts ...KDTreeOption) *KDTree {
 	k2 := *k
@@ -66,10 +89,10 @@ func (k *KDTree) With(opts ...KDTreeOption) *KDTree {
 
 type nodeStack struct {
 	*KDTree
-	nn []*node
+	nn []int32
 }
 
-func (k *KDTree) newNodeStack(n0 *node) *nodeStack {
+func (k *KDTree) newNodeStack(n0 int32) *nodeStack {
 	nodesBuf := k.poolNodeStack.Get().(*nodeStack)
 	nodesBuf.nn = append(nodesBuf.nn[:0], n0)
 	nodesBuf.KDTree = k
@@ -81,7 +104,7 @@ func (ns *nodeStack) cleanup() {
 }
 
 func (k *KDTree) Nearest(p mat.Vec3, maxRange float32) storage.Neighbor {
-	if k.root == nil {
+	if k.root == nilNode {
 		return storage.Neighbor{ID: -1, DistSq: maxRange * maxRange}
 	}
 	nodes := k.newNodeStack(k.root)
@@ -93,9 +116,10 @@ func (k *KDTree) Nearest(p mat.Vec3, maxRange float32) storage.Neighbor {
 
 func (ns *nodeStack) nearestImpl(p mat.Vec3, maxRangeSq float32) storage.Neighbor {
 	i := len(ns.nn) - 1
+	leaf := &ns.nodes[ns.nn[i]]
 	neighbor1 := storage.Neighbor{
-		ID:     ns.nn[i].id,
-		DistSq: (ns.Vec3At(ns.nn[i].id).Sub(p)).NormSq(),
+		ID:     leaf.id,
+		DistSq: (ns.Vec3At(leaf.id).Sub(p)).NormSq(),
 	}
 	if neighbor1.DistSq > maxRangeSq {
 		neighbor1.ID = -1
@@ -105,7 +129,7 @@ func (ns *nodeStack) nearestImpl(p mat.Vec3, maxRangeSq float32) storage.Neighbo
 		return neighbor1
 	}
 	for j := i - 1; j >= 0; j-- {
-		nj := ns.nn[j]
+		nj := &ns.nodes[ns.nn[j]]
 
 		pivot := ns.Vec3At(nj.id)
 		fromPivot := p[nj.dim] - pivot[nj.dim]
@@ -121,13 +145,13 @@ func (ns *nodeStack) nearestImpl(p mat.Vec3, maxRangeSq float32) storage.Neighbo
 				break
 			}
 		}
-		var nextNode *node
+		var nextNode int32
 		if nj.children[0] == ns.nn[j+1] {
 			nextNode = nj.children[1]
 		} else {
 			nextNode = nj.children[0]
 		}
-		if nextNode == nil {
+		if nextNode == nilNode {
 			continue
 		}
 
@@ -147,7 +171,7 @@ func (ns *nodeStack) nearestImpl(p mat.Vec3, maxRangeSq float32) storage.Neighbo
 
 func (k *KDTree) Range(p mat.Vec3, maxRange float32) []storage.Neighbor {
 	neighbors := []storage.Neighbor{}
-	if k.root == nil {
+	if k.root == nilNode {
 		return neighbors
 	}
 	nodes := k.newNodeStack(k.root)
@@ -162,14 +186,15 @@ func (k *KDTree) Range(p mat.Vec3, maxRange float32) []storage.Neighbor {
 
 func (ns *nodeStack) rangeImpl(p mat.Vec3, maxRangeSq float32, neighbors *[]storage.Neighbor) {
 	i := len(ns.nn) - 1
-	id := ns.nn[i].id
+	id := ns.nodes[ns.nn[i]].id
 	dsq := (ns.Vec3At(id).Sub(p)).NormSq()
 	if dsq < maxRangeSq {
 		*neighbors = append(*neighbors, storage.Neighbor{ID: id, DistSq: dsq})
 	}
 	for j := i - 1; j >= 0; j-- {
-		pivot := ns.Vec3At(ns.nn[j].id)
-		dim := ns.nn[j].dim
+		nj := &ns.nodes[ns.nn[j]]
+		pivot := ns.Vec3At(nj.id)
+		dim := nj.dim
 		fromPivot := p[dim] - pivot[dim]
 		fromPivotSq := fromPivot * fromPivot
 		if fromPivotSq > maxRangeSq {
@@ -177,15 +202,15 @@ func (ns *nodeStack) rangeImpl(p mat.Vec3, maxRangeSq float32, neighbors *[]stor
 		}
 		dsqPivot := (pivot.Sub(p)).NormSq()
 		if dsqPivot < maxRangeSq {
-			*neighbors = append(*neighbors, storage.Neighbor{ID: ns.nn[j].id, DistSq: dsqPivot})
+			*neighbors = append(*neighbors, storage.Neighbor{ID: nj.id, DistSq: dsqPivot})
 		}
-		var nextNode *node
-		if ns.nn[j].children[0] == ns.nn[j+1] {
-			nextNode = ns.nn[j].children[1]
+		var nextNode int32
+		if nj.children[0] == ns.nn[j+1] {
+			nextNode = nj.children[1]
 		} else {
-			nextNode = ns.nn[j].children[0]
+			nextNode = nj.children[0]
 		}
-		if nextNode == nil {
+		if nextNode == nilNode {
 			continue
 		}
 
@@ -196,18 +221,73 @@ func (ns *nodeStack) rangeImpl(p mat.Vec3, maxRangeSq float32, neighbors *[]stor
 	}
 }
 
+// KNearest returns at most n nearest neighbors in maxRange sorted by the distance.
+// MinDistSq is not applied to KNearest.
+func (k *KDTree) KNearest(p mat.Vec3, n int, maxRange float32) []storage.Neighbor {
+	if k.root == nilNode || n <= 0 {
+		return []storage.Neighbor{}
+	}
+	nodes := k.newNodeStack(k.root)
+	defer nodes.cleanup()
+
+	h := &neighborMaxHeap{
+		neighbors:  make([]storage.Neighbor, 0, n),
+		k:          n,
+		maxRangeSq: maxRange * maxRange,
+	}
+	nodes.searchLeafNode(p)
+	nodes.kNearestImpl(p, h)
+
+	neighbors := h.neighbors
+	sort.Sort(neighborSorter(neighbors))
+	return neighbors
+}
+
+func (ns *nodeStack) kNearestImpl(p mat.Vec3, h *neighborMaxHeap) {
+	i := len(ns.nn) - 1
+	id := ns.nodes[ns.nn[i]].id
+	h.add(id, (ns.Vec3At(id).Sub(p)).NormSq())
+
+	for j := i - 1; j >= 0; j-- {
+		nj := &ns.nodes[ns.nn[j]]
+
+		pivot := ns.Vec3At(nj.id)
+		fromPivot := p[nj.dim] - pivot[nj.dim]
+		fromPivotSq := fromPivot * fromPivot
+		if fromPivotSq > h.distSq() {
+			continue
+		}
+		h.add(nj.id, (pivot.Sub(p)).NormSq())
+
+		var nextNode int32
+		if nj.children[0] == ns.nn[j+1] {
+			nextNode = nj.children[1]
+		} else {
+			nextNode = nj.children[0]
+		}
+		if nextNode == nilNode {
+			continue
+		}
+
+		nodesNext := ns.newNodeStack(nextNode)
+		nodesNext.searchLeafNode(p)
+		nodesNext.kNearestImpl(p, h)
+		nodesNext.cleanup()
+	}
+}
+
 func (ns *nodeStack) searchLeafNode(p mat.Vec3) {
-	parent := ns.nn[len(ns.nn)-1]
+	parent := &ns.nodes[ns.nn[len(ns.nn)-1]]
 
 	child0, child1 := parent.children[0], parent.children[1]
 	switch {
-	case child0 == nil && child1 == nil:
+	case child0 == nilNode && child1 == nilNode:
 		return
-	case child0 == nil:
+	case child0 == nilNode:
 		ns.nn = append(ns.nn, child1)
 		ns.searchLeafNode(p)
 		return
-	case child1 == nil:
+	case child1 == nilNode:
 		ns.nn = append(ns.nn, child0)
 		ns.searchLeafNode(p)
 		return
@@ -221,14 +301,15 @@ func (ns *nodeStack) searchLeafNode(p mat.Vec3) {
 	ns.searchLeafNode(p)
 }
 
-func (k *KDTree) findMinimumImpl(n *node, dim int, depth int) (int, error) {
+func (k *KDTree) findMinimumImpl(i int32, dim int, depth int) (int, error) {
 	if dim > 2 {
 		return -1, fmt.Errorf("dim should be <3")
 	}
 
-	if n == nil {
+	if i == nilNode {
 		return -1, nil
 	}
+	n := &k.nodes[i]
 
 	minNode := func(d int, nID1, nID2, nID3 int) int {
 		min := nID1
@@ -242,7 +323,7 @@ func (k *KDTree) findMinimumImpl(n *node, dim int, depth int) (int, error) {
 	}
 
 	if n.dim == dim {
-		if n.children[0] == nil {
+		if n.children[0] == nilNode {
 			return n.id, nil
 		}
 		min, err := k.findMinimumImpl(n.children[0], dim, depth+1)
@@ -263,39 +344,42 @@ func (k *KDTree) findMinimumImpl(n *node, dim int, depth int) (int, error) {
 	return minNode(dim, n.id, min0, min1), nil
 }
 
-func (k *KDTree) deleteNodeImpl(n *node, pID int, depth int) (*node, error) {
-	if n == nil {
-		return nil, nil
+func (k *KDTree) deleteNodeImpl(i int32, pID int, depth int) (int32, error) {
+	if i == nilNode {
+		return nilNode, nil
 	}
+	n := &k.nodes[i]
 
 	if pID == n.id {
-		if n.children[1] != nil {
+		if n.children[1] != nilNode {
 			minNodeID, err := k.findMinimumImpl(n.children[1], n.dim, depth)
 			if err != nil {
-				return nil, err
+				return nilNode, err
 			}
 			child, err := k.deleteNodeImpl(n.children[1], minNodeID, depth+1)
 			if err != nil {
-				return nil, err
+				return nilNode, err
 			}
 			n.id = minNodeID
 			n.children[1] = child
-		} else if n.children[0] != nil {
+		} else if n.children[0] != nilNode {
 			minNodeID, err := k.findMinimumImpl(n.children[0], n.dim, depth)
 			if err != nil {
-				return nil, err
+				return nilNode, err
 			}
 			child, err := k.deleteNodeImpl(n.children[0], minNodeID, depth+1)
 			if err != nil {
-				return nil, err
+				return nilNode, err
 			}
 			n.id = minNodeID
-			n.children[0] = nil
+			n.children[0] = nilNode
 			n.children[1] = child
 		} else {
-			return nil, nil
+			k.size--
+			k.free = append(k.free, i)
+			return nilNode, nil
 		}
-		return n, nil
+		return i, nil
 	}
 
 	pointAtNode := k.Vec3At(n.id)
@@ -304,7 +388,7 @@ func (k *KDTree) deleteNodeImpl(n *node, pID int, depth int) (*node, error) {
 	if p[n.dim] <= pointAtNode[n.dim] {
 		child, err := k.deleteNodeImpl(n.children[0], pID, depth+1)
 		if err != nil {
-			return nil, err
+			return nilNode, err
 		}
 		n.children[0] = child
 	}
@@ -312,11 +396,11 @@ func (k *KDTree) deleteNodeImpl(n *node, pID int, depth int) (*node, error) {
 	if p[n.dim] >= pointAtNode[n.dim] {
 		child, err := k.deleteNodeImpl(n.children[1], pID, depth+1)
 		if err != nil {
-			return nil, err
+			return nilNode, err
 		}
 		n.children[1] = child
 	}
-	return n, nil
+	return i, nil
 }
 
 func (k *KDTree) DeletePoint(pID int) error {
@@ -331,8 +415,9 @@ func (k *KDTree) DeletePoint(pID int) error {
 	return nil
 }
 
-func (k *KDTree) stringImpl(n *node, depth int) string {
-	if n != nil {
+func (k *KDTree) stringImpl(i int32, depth int) string {
+	if i != nilNode {
+		n := &k.nodes[i]
 		s := k.stringImpl(n.children[1], depth+1)
 		s += fmt.Sprintf(strings.Repeat(" ", 10*depth)+"-> (%d,%d) %v\n", n.id, n.dim, k.Vec3At(n.id))
 		s += k.stringImpl(n.children[0], depth+1)
@@ -345,83 +430,79 @@ func (k *KDTree) String() string {
 	return k.stringImpl(k.root, 0)
 }
 
-func newNode(ra pc.Vec3RandomAccessor, indice []int, depth int) *node {
-	is := &indiceSorter{
-		ra:     ra,
-		indice: indice,
-		dim:    depth % 3,
-	}
-	sort.Sort(is)
-	mid := len(is.indice) / 2
-	med := is.indice[mid]
-
-	var left, right *node
-	if mid > 0 {
-		left = newNode(ra, indice[:mid], depth+1)
-	}
-	if mid+1 < len(indice) {
-		right = newNode(ra, indice[mid+1:], depth+1)
+func (k *KDTree) maxDepth(i int32, depth int) int {
+	if i == nilNode {
+		return depth
 	}
-	return &node{
-		children: [2]*node{left, right},
-		id:       med,
-		dim:      is.dim,
+	d0 := k.maxDepth(k.nodes[i].children[0], depth+1)
+	d1 := k.maxDepth(k.nodes[i].children[1], depth+1)
+	if d0 > d1 {
+		return d0
 	}
+	return d1
 }
 
-func (n *node) String() string {
-	return n.stringImpl(0)
+type neighborSorter []storage.Neighbor
+
+func (ns neighborSorter) Len() int {
+	return len(ns)
 }
 
-func (n *node) stringImpl(depth int) string {
-	if n == nil {
-		return strings.Repeat(" ", depth) + "nil"
-	}
-	return strings.Repeat(" ", depth) + fmt.Sprintf("%d", n.id) + "\n" +
-		n.children[0].stringImpl(depth+1) + "\n" +
-		n.children[1].stringImpl(depth+1)
+func (ns neighborSorter) Swap(i, j int) {
+	ns[i], ns[j] = ns[j], ns[i]
 }
 
-func (n *node) maxDepth(depth int) int {
-	if n == nil {
-		return depth
-	}
-	d0 := n.children[0].maxDepth(depth + 1)
-	d1 := n.children[1].maxDepth(depth + 1)
-	if d0 > d1 {
-		return d0
-	}
-	return d1
+func (ns neighborSorter) Less(i, j int) bool {
+	return ns[i].DistSq < ns[j].DistSq
 }
 
-type indiceSorter struct {
-	ra     pc.Vec3RandomAccessor
-	indice []int
-	dim    int
+// neighborMaxHeap holds k nearest neighbors.
+// Farthest neighbor is placed at the top of the heap.
+type neighborMaxHeap struct {
+	neighbors  []storage.Neighbor
+	k          int
+	maxRangeSq float32
 }
 
-func (s *indiceSorter) Len() int {
-	return len(s.indice)
+func (h *neighborMaxHeap) Len() int {
+	return len(h.neighbors)
 }
 
-func (s *indiceSorter) Less(i, j int) bool {
-	return s.ra.Vec3At(s.indice[i])[s.dim] < s.ra.Vec3At(s.indice[j])[s.dim]
+func (h *neighborMaxHeap) Swap(i, j int) {
+	h.neighbors[i], h.neighbors[j] = h.neighbors[j], h.neighbors[i]
 }
 
-func (s *indiceSorter) Swap(i, j int) {
-	s.indice[i], s.indice[j] = s.indice[j], s.indice[i]
+func (h *neighborMaxHeap) Less(i, j int) bool {
+	return h.neighbors[i].DistSq > h.neighbors[j].DistSq
 }
 
-type neighborSorter []storage.Neighbor
+func (h *neighborMaxHeap) Push(x interface{}) {
+	h.neighbors = append(h.neighbors, x.(storage.Neighbor))
+}
 
-func (ns neighborSorter) Len() int {
-	return len(ns)
+func (h *neighborMaxHeap) Pop() interface{} {
+	n := len(h.neighbors) - 1
+	x := h.neighbors[n]
+	h.neighbors = h.neighbors[:n]
+	return x
 }
 
-func (ns neighborSorter) Swap(i, j int) {
-	ns[i], ns[j] = ns[j], ns[i]
+// distSq returns squared distance to cut the search branch.
+func (h *neighborMaxHeap) distSq() float32 {
+	if len(h.neighbors) < h.k {
+		return h.maxRangeSq
+	}
+	return h.neighbors[0].DistSq
 }
 
-func (ns neighborSorter) Less(i, j int) bool {
-	return ns[i].DistSq < ns[j].DistSq
+func (h *neighborMaxHeap) add(id int, dsq float32) {
+	if dsq >= h.distSq() {
+		return
+	}
+	if len(h.neighbors) < h.k {
+		heap.Push(h, storage.Neighbor{ID: id, DistSq: dsq})
+		return
+	}
+	h.neighbors[0] = storage.Neighbor{ID: id, DistSq: dsq}
+	heap.Fix(h, 0)
 }
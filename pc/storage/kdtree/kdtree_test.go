@@ -10,9 +10,11 @@ import (
 	"github.com/seqsense/pcgol/mat"
 	"github.com/seqsense/pcgol/pc"
 	"github.com/seqsense/pcgol/pc/storage"
+	"github.com/seqsense/pcgol/pc/storage/storagetest"
 )
 
-var _ storage.Search = &KDTree{} // KDTree must implement storage.Search
+var _ storage.Search = &KDTree{}  // KDTree must implement storage.Search
+var _ storage.KSearch = &KDTree{} // KDTree must implement storage.KSearch
 
 func createTestPointCloud(t *testing.T) pc.Vec3Iterator {
 	t.Helper()
@@ -109,47 +111,81 @@ func TestNode_maxDepth(t *testing.T) {
 		tt := tt
 		t.Run(name, func(t *testing.T) {
 			kdt := New(tt.ra)
-			if d := kdt.root.maxDepth(0); d != tt.expected {
+			if d := kdt.maxDepth(kdt.root, 0); d != tt.expected {
 				t.Errorf("Expected max depth: %d, got: %d\n%s", tt.expected, d, kdt)
 			}
 		})
 	}
 }
 
+// testNode is a pointer based representation of the tree to describe expected trees.
+type testNode struct {
+	children [2]*testNode
+	id       int
+	dim      int
+}
+
+// newTestKDTree builds KDTree from the tree described by testNode.
+func newTestKDTree(ra pc.Vec3RandomAccessor, root *testNode) *KDTree {
+	k := &KDTree{Vec3RandomAccessor: ra}
+	var add func(n *testNode) int32
+	add = func(n *testNode) int32 {
+		if n == nil {
+			return nilNode
+		}
+		i := int32(len(k.nodes))
+		k.nodes = append(k.nodes, node{id: n.id, dim: n.dim})
+		k.size++
+		for c := range n.children {
+			child := add(n.children[c])
+			k.nodes[i].children[c] = child
+		}
+		return i
+	}
+	k.root = add(root)
+	return k
+}
+
 func kdtreeDeepExpectEqual(t *testing.T, a, b *KDTree) bool {
 	t.Helper()
-	return reflect.DeepEqual(a.root, b.root) && reflect.DeepEqual(a.Vec3RandomAccessor, b.Vec3RandomAccessor)
+	var equal func(i, j int32) bool
+	equal = func(i, j int32) bool {
+		if i == nilNode || j == nilNode {
+			return i == j
+		}
+		na, nb := &a.nodes[i], &b.nodes[j]
+		return na.id == nb.id && na.dim == nb.dim &&
+			equal(na.children[0], nb.children[0]) && equal(na.children[1], nb.children[1])
+	}
+	return equal(a.root, b.root) && reflect.DeepEqual(a.Vec3RandomAccessor, b.Vec3RandomAccessor)
 }
 
 func TestKDtree(t *testing.T) {
 	it := createTestPointCloud(t)
 	kdt := New(it)
 
-	expectedTree := &KDTree{
-		Vec3RandomAccessor: it,
-		root: &node{
-			children: [2]*node{
-				&node{
-					children: [2]*node{
-						&node{id: 5, dim: 2},
-						&node{id: 1, dim: 2},
-					},
-					id:  4,
-					dim: 1,
+	expectedTree := newTestKDTree(it, &testNode{
+		children: [2]*testNode{
+			&testNode{
+				children: [2]*testNode{
+					&testNode{id: 5, dim: 2},
+					&testNode{id: 1, dim: 2},
 				},
-				&node{
-					children: [2]*node{
-						&node{id: 2, dim: 2},
-						&node{id: 6, dim: 2},
-					},
-					id:  0,
-					dim: 1,
+				id:  4,
+				dim: 1,
+			},
+			&testNode{
+				children: [2]*testNode{
+					&testNode{id: 2, dim: 2},
+					&testNode{id: 6, dim: 2},
 				},
+				id:  0,
+				dim: 1,
 			},
-			id:  3,
-			dim: 0,
 		},
-	}
+		id:  3,
+		dim: 0,
+	})
 	if !kdtreeDeepExpectEqual(t, expectedTree, kdt) {
 		t.Fatalf("Expected:\n%v\nGot:\n%v", expectedTree, kdt)
 	}
@@ -271,7 +307,7 @@ func TestKDtree(t *testing.T) {
 				nodes := kdt.newNodeStack(kdt.root)
 				defer nodes.cleanup()
 				nodes.searchLeafNode(tt.p)
-				if id := nodes.nn[len(nodes.nn)-1].id; id != tt.nodeID {
+				if id := kdt.nodes[nodes.nn[len(nodes.nn)-1]].id; id != tt.nodeID {
 					t.Errorf("Expected node.id: %d, got: %d", tt.nodeID, id)
 				}
 			})
@@ -421,292 +457,265 @@ func TestKDtree(t *testing.T) {
 				{
 					pID:      5,
 					hasError: false,
-					expectedTree: &KDTree{
-						Vec3RandomAccessor: it,
-						root: &node{
-							children: [2]*node{
-								&node{
-									children: [2]*node{
-										nil,
-										&node{id: 1, dim: 2},
-									},
-									id:  4,
-									dim: 1,
+					expectedTree: newTestKDTree(it, &testNode{
+						children: [2]*testNode{
+							&testNode{
+								children: [2]*testNode{
+									nil,
+									&testNode{id: 1, dim: 2},
 								},
-								&node{
-									children: [2]*node{
-										&node{id: 2, dim: 2},
-										&node{id: 6, dim: 2},
-									},
-									id:  0,
-									dim: 1,
+								id:  4,
+								dim: 1,
+							},
+							&testNode{
+								children: [2]*testNode{
+									&testNode{id: 2, dim: 2},
+									&testNode{id: 6, dim: 2},
 								},
+								id:  0,
+								dim: 1,
 							},
-							id:  3,
-							dim: 0,
 						},
-					},
+						id:  3,
+						dim: 0,
+					}),
 				},
 				{
 					pID:      4,
 					hasError: false,
-					expectedTree: &KDTree{
-						Vec3RandomAccessor: it,
-						root: &node{
-							children: [2]*node{
-								&node{
-									id:  1,
-									dim: 1,
-								},
-								&node{
-									children: [2]*node{
-										&node{id: 2, dim: 2},
-										&node{id: 6, dim: 2},
-									},
-									id:  0,
-									dim: 1,
+					expectedTree: newTestKDTree(it, &testNode{
+						children: [2]*testNode{
+							&testNode{
+								id:  1,
+								dim: 1,
+							},
+							&testNode{
+								children: [2]*testNode{
+									&testNode{id: 2, dim: 2},
+									&testNode{id: 6, dim: 2},
 								},
+								id:  0,
+								dim: 1,
 							},
-							id:  3,
-							dim: 0,
 						},
-					},
+						id:  3,
+						dim: 0,
+					}),
 				},
 			},
 			"RootThenNodeWithLeftSubTree": {
 				{
 					pID:      3,
 					hasError: false,
-					expectedTree: &KDTree{
-						Vec3RandomAccessor: it,
-						root: &node{
-							children: [2]*node{
-								&node{
-									children: [2]*node{
-										&node{
-											id:  5,
-											dim: 2,
-										},
-										&node{
-											id:  1,
-											dim: 2,
-										},
+					expectedTree: newTestKDTree(it, &testNode{
+						children: [2]*testNode{
+							&testNode{
+								children: [2]*testNode{
+									&testNode{
+										id:  5,
+										dim: 2,
+									},
+									&testNode{
+										id:  1,
+										dim: 2,
 									},
-									id:  4,
-									dim: 1,
 								},
-								&node{
-									children: [2]*node{
-										&node{
-											id:  2,
-											dim: 2,
-										},
+								id:  4,
+								dim: 1,
+							},
+							&testNode{
+								children: [2]*testNode{
+									&testNode{
+										id:  2,
+										dim: 2,
 									},
-									id:  6,
-									dim: 1,
 								},
+								id:  6,
+								dim: 1,
 							},
-							id:  0,
-							dim: 0,
 						},
-					},
+						id:  0,
+						dim: 0,
+					}),
 				},
 				{
 					pID:      6,
 					hasError: false,
-					expectedTree: &KDTree{
-						Vec3RandomAccessor: it,
-						root: &node{
-							children: [2]*node{
-								&node{
-									children: [2]*node{
-										&node{
-											id:  5,
-											dim: 2,
-										},
-										&node{
-											id:  1,
-											dim: 2,
-										},
+					expectedTree: newTestKDTree(it, &testNode{
+						children: [2]*testNode{
+							&testNode{
+								children: [2]*testNode{
+									&testNode{
+										id:  5,
+										dim: 2,
+									},
+									&testNode{
+										id:  1,
+										dim: 2,
 									},
-									id:  4,
-									dim: 1,
-								},
-								&node{
-									id:  2,
-									dim: 1,
 								},
+								id:  4,
+								dim: 1,
+							},
+							&testNode{
+								id:  2,
+								dim: 1,
 							},
-							id:  0,
-							dim: 0,
 						},
-					},
+						id:  0,
+						dim: 0,
+					}),
 				},
 			},
 			"NodeWithBothLeftAndRightSubTrees": {
 				{
 					pID:      0,
 					hasError: false,
-					expectedTree: &KDTree{
-						Vec3RandomAccessor: it,
-						root: &node{
-							children: [2]*node{
-								&node{
-									children: [2]*node{
-										&node{id: 5, dim: 2},
-										&node{id: 1, dim: 2},
-									},
-									id:  4,
-									dim: 1,
+					expectedTree: newTestKDTree(it, &testNode{
+						children: [2]*testNode{
+							&testNode{
+								children: [2]*testNode{
+									&testNode{id: 5, dim: 2},
+									&testNode{id: 1, dim: 2},
 								},
-								&node{
-									children: [2]*node{
-										&node{id: 2, dim: 2},
-									},
-									id:  6,
-									dim: 1,
+								id:  4,
+								dim: 1,
+							},
+							&testNode{
+								children: [2]*testNode{
+									&testNode{id: 2, dim: 2},
 								},
+								id:  6,
+								dim: 1,
 							},
-							id:  3,
-							dim: 0,
 						},
-					},
+						id:  3,
+						dim: 0,
+					}),
 				},
 			},
 			"TwiceTheSamePoint": {
 				{
 					pID:      3,
 					hasError: false,
-					expectedTree: &KDTree{
-						Vec3RandomAccessor: it,
-						root: &node{
-							children: [2]*node{
-								&node{
-									children: [2]*node{
-										&node{
-											id:  5,
-											dim: 2,
-										},
-										&node{
-											id:  1,
-											dim: 2,
-										},
+					expectedTree: newTestKDTree(it, &testNode{
+						children: [2]*testNode{
+							&testNode{
+								children: [2]*testNode{
+									&testNode{
+										id:  5,
+										dim: 2,
+									},
+									&testNode{
+										id:  1,
+										dim: 2,
 									},
-									id:  4,
-									dim: 1,
 								},
-								&node{
-									children: [2]*node{
-										&node{
-											id:  2,
-											dim: 2,
-										},
+								id:  4,
+								dim: 1,
+							},
+							&testNode{
+								children: [2]*testNode{
+									&testNode{
+										id:  2,
+										dim: 2,
 									},
-									id:  6,
-									dim: 1,
 								},
+								id:  6,
+								dim: 1,
 							},
-							id:  0,
-							dim: 0,
 						},
-					},
+						id:  0,
+						dim: 0,
+					}),
 				},
 				{
 					pID:      3,
 					hasError: false,
-					expectedTree: &KDTree{
-						Vec3RandomAccessor: it,
-						root: &node{
-							children: [2]*node{
-								&node{
-									children: [2]*node{
-										&node{
-											id:  5,
-											dim: 2,
-										},
-										&node{
-											id:  1,
-											dim: 2,
-										},
+					expectedTree: newTestKDTree(it, &testNode{
+						children: [2]*testNode{
+							&testNode{
+								children: [2]*testNode{
+									&testNode{
+										id:  5,
+										dim: 2,
+									},
+									&testNode{
+										id:  1,
+										dim: 2,
 									},
-									id:  4,
-									dim: 1,
 								},
-								&node{
-									children: [2]*node{
-										&node{
-											id:  2,
-											dim: 2,
-										},
+								id:  4,
+								dim: 1,
+							},
+							&testNode{
+								children: [2]*testNode{
+									&testNode{
+										id:  2,
+										dim: 2,
 									},
-									id:  6,
-									dim: 1,
 								},
+								id:  6,
+								dim: 1,
 							},
-							id:  0,
-							dim: 0,
 						},
-					},
+						id:  0,
+						dim: 0,
+					}),
 				},
 			},
 			"InvalidPointID": {
 				{
 					pID:      -1,
 					hasError: true,
-					expectedTree: &KDTree{
-						Vec3RandomAccessor: it,
-						root: &node{
-							children: [2]*node{
-								&node{
-									children: [2]*node{
-										&node{id: 5, dim: 2},
-										&node{id: 1, dim: 2},
-									},
-									id:  4,
-									dim: 1,
+					expectedTree: newTestKDTree(it, &testNode{
+						children: [2]*testNode{
+							&testNode{
+								children: [2]*testNode{
+									&testNode{id: 5, dim: 2},
+									&testNode{id: 1, dim: 2},
 								},
-								&node{
-									children: [2]*node{
-										&node{id: 2, dim: 2},
-										&node{id: 6, dim: 2},
-									},
-									id:  0,
-									dim: 1,
+								id:  4,
+								dim: 1,
+							},
+							&testNode{
+								children: [2]*testNode{
+									&testNode{id: 2, dim: 2},
+									&testNode{id: 6, dim: 2},
 								},
+								id:  0,
+								dim: 1,
 							},
-							id:  3,
-							dim: 0,
 						},
-					},
+						id:  3,
+						dim: 0,
+					}),
 				},
 				{
 					pID:      123,
 					hasError: true,
-					expectedTree: &KDTree{
-						Vec3RandomAccessor: it,
-						root: &node{
-							children: [2]*node{
-								&node{
-									children: [2]*node{
-										&node{id: 5, dim: 2},
-										&node{id: 1, dim: 2},
-									},
-									id:  4,
-									dim: 1,
+					expectedTree: newTestKDTree(it, &testNode{
+						children: [2]*testNode{
+							&testNode{
+								children: [2]*testNode{
+									&testNode{id: 5, dim: 2},
+									&testNode{id: 1, dim: 2},
 								},
-								&node{
-									children: [2]*node{
-										&node{id: 2, dim: 2},
-										&node{id: 6, dim: 2},
-									},
-									id:  0,
-									dim: 1,
+								id:  4,
+								dim: 1,
+							},
+							&testNode{
+								children: [2]*testNode{
+									&testNode{id: 2, dim: 2},
+									&testNode{id: 6, dim: 2},
 								},
+								id:  0,
+								dim: 1,
 							},
-							id:  3,
-							dim: 0,
 						},
-					},
+						id:  3,
+						dim: 0,
+					}),
 				},
 			},
 		}
@@ -791,7 +800,7 @@ func ExampleKDTree_String() {
 	//                     -> (5,2) {1.000, 0.000, 0.000}
 }
 
-func testNearestRandomCloud(t *testing.T, it pc.Vec3Iterator, k *KDTree, ns *naiveSearch, nPoints int, width float32) {
+func testNearestRandomCloud(t *testing.T, it pc.Vec3RandomAccessor, k *KDTree, ns *naiveSearch, nPoints int, width float32) {
 	for i := 0; i < nPoints; i++ {
 		p := randomPoint(width)
 		maxRange := rand.Float32() * width
@@ -923,6 +932,113 @@ func TestKDtree_Range_randomCloud(t *testing.T) {
 	}
 }
 
+func TestKDtree_KNearest_randomCloud(t *testing.T) {
+	const (
+		nPoints = 100
+		width   = 10.0
+	)
+
+	pp := generateRandomCloud(t, nPoints, width)
+	it, err := pp.Vec3Iterator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	kdt := New(it)
+	ns := newNaiveSearch(it)
+	for i := 0; i < nPoints; i++ {
+		p := randomPoint(width)
+		maxRange := rand.Float32() * width
+		k := rand.Intn(10)
+
+		neighborsNaive := ns.KNearest(p, k, maxRange)
+		neighborsKDTree := kdt.KNearest(p, k, maxRange)
+
+		for i := 1; i < len(neighborsKDTree); i++ {
+			if neighborsKDTree[i].DistSq < neighborsKDTree[i-1].DistSq {
+				t.Fatalf("Neighbors are not sorted")
+			}
+		}
+
+		sort.Sort(neighborIDSorter(neighborsNaive))
+		sort.Sort(neighborIDSorter(neighborsKDTree))
+
+		if !reflect.DeepEqual(neighborsNaive, neighborsKDTree) {
+			t.Fatalf(
+				"%d %d %0.3f %s: Expected: %v, got %v", i, k, maxRange, p, neighborsNaive, neighborsKDTree,
+			)
+		}
+	}
+}
+
+func TestKDtree_KNearest(t *testing.T) {
+	it := createTestPointCloud(t)
+	kdt := New(it)
+
+	testCases := map[string]struct {
+		p         mat.Vec3
+		k         int
+		maxRange  float32
+		neighbors []storage.Neighbor
+	}{
+		"Zero": {
+			p:         mat.Vec3{3, 0, 0},
+			k:         0,
+			maxRange:  10,
+			neighbors: []storage.Neighbor{},
+		},
+		"OutOfRange": {
+			p:         mat.Vec3{10, 10, 10},
+			k:         3,
+			maxRange:  1,
+			neighbors: []storage.Neighbor{},
+		},
+		"K=4": {
+			p:        mat.Vec3{3, 0, 0},
+			k:        4,
+			maxRange: 10,
+			neighbors: []storage.Neighbor{
+				{ID: 3, DistSq: 0},
+				{ID: 0, DistSq: 2},
+				{ID: 2, DistSq: 4},
+				{ID: 5, DistSq: 4},
+			},
+		},
+		"LimitedByRange": {
+			p:        mat.Vec3{3, 0, 0},
+			k:        3,
+			maxRange: 1.5,
+			neighbors: []storage.Neighbor{
+				{ID: 3, DistSq: 0},
+				{ID: 0, DistSq: 2},
+			},
+		},
+		"All": {
+			p:        mat.Vec3{3, 0, 0},
+			k:        10,
+			maxRange: 10,
+			neighbors: []storage.Neighbor{
+				{ID: 3, DistSq: 0},
+				{ID: 0, DistSq: 2},
+				{ID: 2, DistSq: 4},
+				{ID: 5, DistSq: 4},
+				{ID: 1, DistSq: 6},
+				{ID: 4, DistSq: 10},
+				{ID: 6, DistSq: 14},
+			},
+		},
+	}
+	for name, tt := range testCases {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			neighbors := kdt.KNearest(tt.p, tt.k, tt.maxRange)
+			sort.Sort(neighborIDSorter(neighbors))
+			if !reflect.DeepEqual(tt.neighbors, neighbors) {
+				t.Errorf("Expected: %v, got: %v", tt.neighbors, neighbors)
+			}
+		})
+	}
+}
+
 type neighborIDSorter []storage.Neighbor
 
 func (ns neighborIDSorter) Len() int {
@@ -984,6 +1100,14 @@ func (s *naiveSearch) Range(p mat.Vec3, maxRange float32) []storage.Neighbor {
 	return neighbors
 }
 
+func (s *naiveSearch) KNearest(p mat.Vec3, k int, maxRange float32) []storage.Neighbor {
+	neighbors := s.Range(p, maxRange)
+	if len(neighbors) > k {
+		neighbors = neighbors[:k]
+	}
+	return neighbors
+}
+
 func (s *naiveSearch) findMinimum(dim int) int {
 	id := -1
 	var min float32
@@ -1084,3 +1208,31 @@ func BenchmarkKDTree_Nearest(b *testing.B) {
 		})
 	}
 }
+
+func TestKDTree_conformance(t *testing.T) {
+	t.Run("New", func(t *testing.T) {
+		storagetest.TestSearch(t, func(t *testing.T, ra pc.Vec3RandomAccessor) storage.Search {
+			return New(ra)
+		})
+	})
+	t.Run("Insert", func(t *testing.T) {
+		storagetest.TestSearch(t, func(t *testing.T, ra pc.Vec3RandomAccessor) storage.Search {
+			kdt := New(pc.Vec3Slice{})
+			kdt.Vec3RandomAccessor = ra
+			for i := 0; i < ra.Len(); i++ {
+				if err := kdt.Insert(i); err != nil {
+					t.Fatal(err)
+				}
+			}
+			return kdt
+		})
+	})
+	t.Run("MinDistSq", func(t *testing.T) {
+		const minDist = 0.3
+		storagetest.TestApproxNearest(t, func(t *testing.T, ra pc.Vec3RandomAccessor) storage.Search {
+			return New(ra, func(k *KDTree) {
+				k.MinDistSq = minDist * minDist
+			})
+		}, minDist)
+	})
+}
@@ -0,0 +1,197 @@
+package kdtree
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+
+	"github.com/seqsense/pcgol/pc"
+)
+
+var (
+	ErrInvalidFormat      = errors.New("invalid kdtree format")
+	ErrUnsupportedVersion = errors.New("unsupported kdtree format version")
+	ErrChecksumMismatch   = errors.New("point cloud checksum mismatch")
+)
+
+const serializeVersion = 1
+
+var serializeMagic = [8]byte{'P', 'C', 'G', 'O', 'L', 'K', 'D', 'T'}
+
+const (
+	nodeFlagDimMask  = 0x03
+	nodeFlagHasLeft  = 0x04
+	nodeFlagHasRight = 0x08
+)
+
+type serializeHeader struct {
+	Magic    [8]byte
+	Version  uint32
+	Points   uint32
+	Nodes    uint32
+	Checksum uint64
+}
+
+// WriteTo writes the tree structure to w.
+// Point coordinates are not stored, but their checksum is stored
+// to validate the point accessor passed to Read.
+func (k *KDTree) WriteTo(w io.Writer) (int64, error) {
+	cw := &countWriter{w: w}
+	bw := bufio.NewWriter(cw)
+
+	header := serializeHeader{
+		Magic:    serializeMagic,
+		Version:  serializeVersion,
+		Points:   uint32(k.Len()),
+		Nodes:    uint32(k.subtreeSize(k.root)),
+		Checksum: checksum(k.Vec3RandomAccessor),
+	}
+	if err := binary.Write(bw, binary.LittleEndian, &header); err != nil {
+		return cw.n, err
+	}
+
+	var buf [5]byte
+	var writeNode func(i int32) error
+	writeNode = func(i int32) error {
+		n := &k.nodes[i]
+		flags := byte(n.dim) & nodeFlagDimMask
+		if n.children[0] != nilNode {
+			flags |= nodeFlagHasLeft
+		}
+		if n.children[1] != nilNode {
+			flags |= nodeFlagHasRight
+		}
+		binary.LittleEndian.PutUint32(buf[:4], uint32(n.id))
+		buf[4] = flags
+		if _, err := bw.Write(buf[:]); err != nil {
+			return err
+		}
+		for _, c := range n.children {
+			if c != nilNode {
+				if err := writeNode(c); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	if k.root != nilNode {
+		if err := writeNode(k.root); err != nil {
+			return cw.n, err
+		}
+	}
+	err := bw.Flush()
+	return cw.n, err
+}
+
+// Read loads the tree written by KDTree.WriteTo.
+// ra must provide the same points used to build the stored tree.
+func Read(r io.Reader, ra pc.Vec3RandomAccessor, opts ...KDTreeOption) (*KDTree, error) {
+	br := bufio.NewReader(r)
+
+	var header serializeHeader
+	if err := binary.Read(br, binary.LittleEndian, &header); err != nil {
+		return nil, err
+	}
+	if header.Magic != serializeMagic {
+		return nil, ErrInvalidFormat
+	}
+	if header.Version != serializeVersion {
+		return nil, ErrUnsupportedVersion
+	}
+	if int(header.Points) != ra.Len() || header.Checksum != checksum(ra) {
+		return nil, ErrChecksumMismatch
+	}
+	if header.Nodes > header.Points {
+		return nil, ErrInvalidFormat
+	}
+
+	nodes := make([]node, header.Nodes)
+	var iNode int
+	var buf [5]byte
+	var readNode func() (int32, error)
+	readNode = func() (int32, error) {
+		if iNode >= len(nodes) {
+			return nilNode, ErrInvalidFormat
+		}
+		if _, err := io.ReadFull(br, buf[:]); err != nil {
+			return nilNode, err
+		}
+		ni := int32(iNode)
+		n := &nodes[ni]
+		iNode++
+
+		n.id = int(binary.LittleEndian.Uint32(buf[:4]))
+		if n.id >= ra.Len() {
+			return nilNode, fmt.Errorf("%w: node id %d is out of range", ErrInvalidFormat, n.id)
+		}
+		flags := buf[4]
+		n.dim = int(flags & nodeFlagDimMask)
+		if n.dim > 2 {
+			return nilNode, ErrInvalidFormat
+		}
+		n.children = [2]int32{nilNode, nilNode}
+		for i, mask := range []byte{nodeFlagHasLeft, nodeFlagHasRight} {
+			if flags&mask == 0 {
+				continue
+			}
+			c, err := readNode()
+			if err != nil {
+				return nilNode, err
+			}
+			n.children[i] = c
+		}
+		return ni, nil
+	}
+
+	kdt := &KDTree{
+		Vec3RandomAccessor: ra,
+		nodes:              nodes,
+		root:               nilNode,
+		workers:            1,
+		size:               len(nodes),
+	}
+	for _, o := range opts {
+		o(kdt)
+	}
+	if len(nodes) > 0 {
+		root, err := readNode()
+		if err != nil {
+			return nil, err
+		}
+		if iNode != len(nodes) {
+			return nil, ErrInvalidFormat
+		}
+		kdt.root = root
+	}
+	kdt.initPool()
+	return kdt, nil
+}
+
+func checksum(ra pc.Vec3RandomAccessor) uint64 {
+	h := fnv.New64a()
+	var buf [12]byte
+	for i := 0; i < ra.Len(); i++ {
+		p := ra.Vec3At(i)
+		binary.LittleEndian.PutUint32(buf[0:], math.Float32bits(p[0]))
+		binary.LittleEndian.PutUint32(buf[4:], math.Float32bits(p[1]))
+		binary.LittleEndian.PutUint32(buf[8:], math.Float32bits(p[2]))
+		_, _ = h.Write(buf[:])
+	}
+	return h.Sum64()
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countWriter) Write(b []byte) (int, error) {
+	n, err := w.w.Write(b)
+	w.n += int64(n)
+	return n, err
+}
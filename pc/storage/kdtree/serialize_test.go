@@ -0,0 +1,116 @@
+package kdtree
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/seqsense/pcgol/mat"
+	"github.com/seqsense/pcgol/pc"
+)
+
+func TestKDTree_WriteTo_Read(t *testing.T) {
+	const (
+		nPoints = 1000
+		width   = 10.0
+	)
+
+	pp := generateRandomCloud(t, nPoints, width)
+	it, err := pp.Vec3Iterator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	kdt := New(it)
+	for _, id := range []int{3, 100, 500} {
+		if err := kdt.DeletePoint(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	n, err := kdt.WriteTo(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("Expected written size: %d, got: %d", buf.Len(), n)
+	}
+	data := buf.Bytes()
+
+	t.Run("Restore", func(t *testing.T) {
+		kdt2, err := Read(bytes.NewReader(data), it)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !kdtreeDeepExpectEqual(t, kdt, kdt2) {
+			t.Fatalf("Expected:\n%v\nGot:\n%v", kdt, kdt2)
+		}
+		for i := 0; i < 100; i++ {
+			p := randomPoint(width)
+			if n1, n2 := kdt.Nearest(p, width), kdt2.Nearest(p, width); n1 != n2 {
+				t.Fatalf("Expected: %v, got: %v", n1, n2)
+			}
+		}
+	})
+	t.Run("Options", func(t *testing.T) {
+		kdt2, err := Read(bytes.NewReader(data), it, func(k *KDTree) {
+			k.MinDistSq = 0.1
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if kdt2.MinDistSq != 0.1 {
+			t.Errorf("Option is not applied")
+		}
+	})
+	t.Run("PointMismatch", func(t *testing.T) {
+		ra := make(pc.Vec3Slice, nPoints)
+		for i := range ra {
+			ra[i] = it.Vec3At(i)
+		}
+		ra[10] = ra[10].Add(mat.Vec3{0, 0, 0.001})
+		if _, err := Read(bytes.NewReader(data), ra); !errors.Is(err, ErrChecksumMismatch) {
+			t.Errorf("Expected error: %v, got: %v", ErrChecksumMismatch, err)
+		}
+		if _, err := Read(bytes.NewReader(data), ra[:nPoints-1]); !errors.Is(err, ErrChecksumMismatch) {
+			t.Errorf("Expected error: %v, got: %v", ErrChecksumMismatch, err)
+		}
+	})
+	t.Run("InvalidMagic", func(t *testing.T) {
+		d := append([]byte{}, data...)
+		d[0] = 'X'
+		if _, err := Read(bytes.NewReader(d), it); !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("Expected error: %v, got: %v", ErrInvalidFormat, err)
+		}
+	})
+	t.Run("UnsupportedVersion", func(t *testing.T) {
+		d := append([]byte{}, data...)
+		d[8] = serializeVersion + 1
+		if _, err := Read(bytes.NewReader(d), it); !errors.Is(err, ErrUnsupportedVersion) {
+			t.Errorf("Expected error: %v, got: %v", ErrUnsupportedVersion, err)
+		}
+	})
+	t.Run("Truncated", func(t *testing.T) {
+		if _, err := Read(bytes.NewReader(data[:len(data)-1]), it); err == nil {
+			t.Error("Expected error")
+		}
+	})
+}
+
+func TestKDTree_WriteTo_Read_empty(t *testing.T) {
+	kdt := New(pc.Vec3Slice{})
+	buf := &bytes.Buffer{}
+	if _, err := kdt.WriteTo(buf); err != nil {
+		t.Fatal(err)
+	}
+	kdt2, err := Read(buf, pc.Vec3Slice{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kdt2.root != nilNode {
+		t.Errorf("Expected empty tree, got:\n%v", kdt2)
+	}
+	if n := kdt2.Nearest(mat.Vec3{}, 1); n.ID != -1 {
+		t.Errorf("Expected no neighbor, got: %v", n)
+	}
+}
@@ -0,0 +1,36 @@
+package kdtree
+
+import (
+	"math"
+	"sort"
+
+	"github.com/seqsense/pcgol/mat"
+	"github.com/seqsense/pcgol/pc/storage"
+)
+
+// Inside returns indice of the points inside the volume in ascending order.
+// Subtrees whose bounding box doesn't intersect with the volume are skipped.
+func (k *KDTree) Inside(v storage.Volume) []int {
+	out := []int{}
+	inf := float32(math.Inf(1))
+	k.insideImpl(k.root, v, mat.Vec3{-inf, -inf, -inf}, mat.Vec3{inf, inf, inf}, &out)
+	sort.Ints(out)
+	return out
+}
+
+func (k *KDTree) insideImpl(i int32, v storage.Volume, min, max mat.Vec3, out *[]int) {
+	if i == nilNode || !v.IntersectsBox(min, max) {
+		return
+	}
+	n := &k.nodes[i]
+	p := k.Vec3At(n.id)
+	if v.Contains(p) {
+		*out = append(*out, n.id)
+	}
+	max0 := max
+	max0[n.dim] = p[n.dim]
+	k.insideImpl(n.children[0], v, min, max0, out)
+	min1 := min
+	min1[n.dim] = p[n.dim]
+	k.insideImpl(n.children[1], v, min1, max, out)
+}
@@ -0,0 +1,63 @@
+package kdtree
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/seqsense/pcgol/mat"
+	"github.com/seqsense/pcgol/pc"
+	"github.com/seqsense/pcgol/pc/storage"
+)
+
+var _ storage.VolumeSearch = &KDTree{} // KDTree must implement storage.VolumeSearch
+
+func TestKDTree_Inside(t *testing.T) {
+	const (
+		nPoints = 1000
+		width   = 10.0
+	)
+
+	pp := generateRandomCloud(t, nPoints, width)
+	it, err := pp.Vec3Iterator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	kdt := New(it)
+
+	view := mat.Rotate(0, 1, 0, -math.Pi/2).Mul(mat.Translate(-5, -5, -5))
+	volumes := map[string]storage.Volume{
+		"AABB": storage.AABB{Min: mat.Vec3{1, 2, 3}, Max: mat.Vec3{4, 6, 8}},
+		"OrientedBox": storage.NewOrientedBox(
+			mat.Translate(5, 5, 5).Mul(mat.Rotate(1, 1, 0, 0.5)),
+			mat.Vec3{4, 2, 3},
+		),
+		"Frustum": storage.NewFrustum(mat.Perspective(0.8, 1.5, 0.5, 4).Mul(view)),
+		"Predicate": storage.NewPredicate(mat.Vec3{2, 2, 2}, mat.Vec3{8, 8, 8}, func(p mat.Vec3) bool {
+			return p.Sub(mat.Vec3{5, 5, 5}).NormSq() < 9
+		}),
+		"Empty": storage.AABB{Min: mat.Vec3{20, 20, 20}, Max: mat.Vec3{21, 21, 21}},
+	}
+	for name, v := range volumes {
+		v := v
+		t.Run(name, func(t *testing.T) {
+			expected := insideNaive(it, v)
+			if name != "Empty" && len(expected) == 0 {
+				t.Fatal("Test volume must contain some points")
+			}
+			if ids := kdt.Inside(v); !reflect.DeepEqual(expected, ids) {
+				t.Errorf("Expected %v, got %v", expected, ids)
+			}
+		})
+	}
+}
+
+func insideNaive(ra pc.Vec3RandomAccessor, v storage.Volume) []int {
+	out := []int{}
+	for i := 0; i < ra.Len(); i++ {
+		if v.Contains(ra.Vec3At(i)) {
+			out = append(out, i)
+		}
+	}
+	return out
+}
@@ -0,0 +1,210 @@
+// Package occupancy provides probabilistic 3D occupancy grid map
+// updated by log-odds sensor model like OctoMap.
+package occupancy
+
+import (
+	"math"
+	"sort"
+
+	"github.com/seqsense/pcgol/mat"
+	"github.com/seqsense/pcgol/pc"
+	"github.com/seqsense/pcgol/pc/storage/voxelgrid"
+)
+
+type State int
+
+const (
+	Unknown State = iota
+	Free
+	Occupied
+)
+
+func (s State) String() string {
+	switch s {
+	case Free:
+		return "Free"
+	case Occupied:
+		return "Occupied"
+	default:
+		return "Unknown"
+	}
+}
+
+// Grid is a sparse occupancy grid.
+// Voxel addressing is same as voxelgrid.SparseVoxelGrid.
+type Grid struct {
+	Options
+
+	addr    *voxelgrid.SparseVoxelGrid
+	logOdds map[[3]int]float32
+
+	hit, miss          float32
+	clampMin, clampMax float32
+	threshold          float32
+}
+
+func New(resolution float32, origin mat.Vec3, opts ...Option) *Grid {
+	g := &Grid{
+		Options: defaultOptions,
+		addr:    voxelgrid.NewSparse(resolution, origin),
+		logOdds: make(map[[3]int]float32),
+	}
+	for _, o := range opts {
+		o(&g.Options)
+	}
+	g.hit = logOdds(g.ProbHit)
+	g.miss = logOdds(g.ProbMiss)
+	g.clampMin = logOdds(g.ClampMin)
+	g.clampMax = logOdds(g.ClampMax)
+	g.threshold = logOdds(g.OccupiedThreshold)
+	return g
+}
+
+func logOdds(p float32) float32 {
+	return float32(math.Log(float64(p / (1 - p))))
+}
+
+// Probability converts log-odds to probability.
+func Probability(l float32) float32 {
+	return 1 - 1/(1+float32(math.Exp(float64(l))))
+}
+
+func (g *Grid) Resolution() float32 {
+	return g.addr.Resolution()
+}
+
+func (g *Grid) Origin() mat.Vec3 {
+	return g.addr.Origin()
+}
+
+// Insert integrates the scan measured from sensorOrigin.
+// Voxels along the rays are updated as free and voxels at the end points
+// are updated as occupied. Each voxel is updated at most once per scan
+// and occupied update takes precedence.
+func (g *Grid) Insert(sensorOrigin mat.Vec3, ra pc.Vec3RandomAccessor) {
+	free := make(map[[3]int]bool)
+	occupied := make(map[[3]int]bool)
+
+	origin, res := g.Origin(), g.Resolution()
+	for i := 0; i < ra.Len(); i++ {
+		p := ra.Vec3At(i)
+		hit := true
+		if g.MaxRange > 0 {
+			if d := p.Sub(sensorOrigin); d.NormSq() > g.MaxRange*g.MaxRange {
+				p = sensorOrigin.Add(d.Normalized().Mul(g.MaxRange))
+				hit = false
+			}
+		}
+		end, ok := g.addr.PosInt(p)
+		if !ok {
+			continue
+		}
+		voxelgrid.Traverse(origin, res, sensorOrigin, p, func(pos [3]int) bool {
+			if pos != end {
+				free[pos] = true
+			}
+			return true
+		})
+		if hit {
+			occupied[end] = true
+		} else {
+			free[end] = true
+		}
+	}
+
+	for pos := range free {
+		if !occupied[pos] {
+			g.update(pos, g.miss)
+		}
+	}
+	for pos := range occupied {
+		g.update(pos, g.hit)
+	}
+}
+
+func (g *Grid) update(pos [3]int, l float32) {
+	v := g.logOdds[pos] + l
+	if v < g.clampMin {
+		v = g.clampMin
+	}
+	if v > g.clampMax {
+		v = g.clampMax
+	}
+	g.logOdds[pos] = v
+}
+
+// LogOdds returns the log-odds of the voxel.
+// It returns false if the voxel is never observed.
+func (g *Grid) LogOdds(p mat.Vec3) (float32, bool) {
+	pos, ok := g.addr.PosInt(p)
+	if !ok {
+		return 0, false
+	}
+	return g.LogOddsByPosInt(pos)
+}
+
+func (g *Grid) LogOddsByPosInt(pos [3]int) (float32, bool) {
+	l, ok := g.logOdds[pos]
+	return l, ok
+}
+
+func (g *Grid) State(p mat.Vec3) State {
+	pos, ok := g.addr.PosInt(p)
+	if !ok {
+		return Unknown
+	}
+	return g.StateByPosInt(pos)
+}
+
+func (g *Grid) StateByPosInt(pos [3]int) State {
+	l, ok := g.logOdds[pos]
+	switch {
+	case !ok:
+		return Unknown
+	case l > g.threshold:
+		return Occupied
+	default:
+		return Free
+	}
+}
+
+func (g *Grid) PosInt(p mat.Vec3) ([3]int, bool) {
+	return g.addr.PosInt(p)
+}
+
+// Center returns the center of the voxel.
+func (g *Grid) Center(pos [3]int) mat.Vec3 {
+	res := g.Resolution()
+	return g.Origin().Add(mat.Vec3{
+		float32(pos[0]) * res,
+		float32(pos[1]) * res,
+		float32(pos[2]) * res,
+	})
+}
+
+// Len returns the number of the observed voxels.
+func (g *Grid) Len() int {
+	return len(g.logOdds)
+}
+
+// Occupied returns the integer positions of the occupied voxels in lexicographical order.
+func (g *Grid) Occupied() [][3]int {
+	var out [][3]int
+	for pos, l := range g.logOdds {
+		if l > g.threshold {
+			out = append(out, pos)
+		}
+	}
+	sort.Sort(voxelgrid.PosSorter(out))
+	return out
+}
+
+// PointCloud returns the centers of the occupied voxels.
+func (g *Grid) PointCloud() *pc.PointCloud {
+	occupied := g.Occupied()
+	points := make([]mat.Vec3, len(occupied))
+	for i, pos := range occupied {
+		points[i] = g.Center(pos)
+	}
+	return pc.NewXYZPointCloud(points)
+}
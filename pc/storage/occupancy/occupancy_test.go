@@ -0,0 +1,103 @@
+package occupancy
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/seqsense/pcgol/mat"
+	"github.com/seqsense/pcgol/pc"
+)
+
+func TestGrid(t *testing.T) {
+	g := New(1, mat.Vec3{})
+	scan := pc.Vec3Slice{
+		{3, 0, 0},
+		{0, 2, 0},
+	}
+	g.Insert(mat.Vec3{}, scan)
+
+	testCases := map[string]struct {
+		p        mat.Vec3
+		expected State
+	}{
+		"Origin":   {p: mat.Vec3{0, 0, 0}, expected: Free},
+		"RayX":     {p: mat.Vec3{1, 0, 0}, expected: Free},
+		"HitX":     {p: mat.Vec3{3, 0, 0}, expected: Occupied},
+		"HitY":     {p: mat.Vec3{0, 2, 0}, expected: Occupied},
+		"Behind":   {p: mat.Vec3{4, 0, 0}, expected: Unknown},
+		"Unknown":  {p: mat.Vec3{0, 0, 5}, expected: Unknown},
+		"Diagonal": {p: mat.Vec3{2, 2, 0}, expected: Unknown},
+	}
+	for name, tt := range testCases {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			if s := g.State(tt.p); s != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, s)
+			}
+		})
+	}
+
+	// Origin voxel is passed by two rays but updated once.
+	if l, _ := g.LogOdds(mat.Vec3{}); l != g.miss {
+		t.Errorf("Expected log-odds: %f, got: %f", g.miss, l)
+	}
+
+	pp := g.PointCloud()
+	it, err := pp.Vec3Iterator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var centers []mat.Vec3
+	for i := 0; i < it.Len(); i++ {
+		centers = append(centers, it.Vec3At(i))
+	}
+	expected := []mat.Vec3{{0, 2, 0}, {3, 0, 0}}
+	if !reflect.DeepEqual(expected, centers) {
+		t.Errorf("Expected %v, got %v", expected, centers)
+	}
+}
+
+func TestGrid_Clamp(t *testing.T) {
+	g := New(0.5, mat.Vec3{})
+	for i := 0; i < 20; i++ {
+		g.Insert(mat.Vec3{}, pc.Vec3Slice{{2, 0, 0}})
+	}
+	l, ok := g.LogOdds(mat.Vec3{2, 0, 0})
+	if !ok || l != g.clampMax {
+		t.Errorf("Expected log-odds: %f, got: %f", g.clampMax, l)
+	}
+	if p := Probability(l); p < g.ClampMax-1e-5 || g.ClampMax+1e-5 < p {
+		t.Errorf("Expected probability: %f, got: %f", g.ClampMax, p)
+	}
+
+	// Dynamic obstacle is removed by the following observations.
+	g.Insert(mat.Vec3{}, pc.Vec3Slice{{5, 0, 0}})
+	if s := g.State(mat.Vec3{2, 0, 0}); s != Occupied {
+		t.Errorf("Expected %v, got %v", Occupied, s)
+	}
+	for i := 0; i < 10; i++ {
+		g.Insert(mat.Vec3{}, pc.Vec3Slice{{5, 0, 0}})
+	}
+	if s := g.State(mat.Vec3{2, 0, 0}); s != Free {
+		t.Errorf("Expected %v, got %v", Free, s)
+	}
+	l, _ = g.LogOdds(mat.Vec3{1, 0, 0})
+	if l != g.clampMin {
+		t.Errorf("Expected log-odds: %f, got: %f", g.clampMin, l)
+	}
+}
+
+func TestGrid_MaxRange(t *testing.T) {
+	g := New(1, mat.Vec3{}, WithMaxRange(3))
+	g.Insert(mat.Vec3{}, pc.Vec3Slice{{10, 0, 0}})
+
+	if s := g.State(mat.Vec3{3, 0, 0}); s != Free {
+		t.Errorf("Truncated end point: expected %v, got %v", Free, s)
+	}
+	if s := g.State(mat.Vec3{4, 0, 0}); s != Unknown {
+		t.Errorf("Out of range: expected %v, got %v", Unknown, s)
+	}
+	if s := g.State(mat.Vec3{10, 0, 0}); s != Unknown {
+		t.Errorf("Out of range: expected %v, got %v", Unknown, s)
+	}
+}
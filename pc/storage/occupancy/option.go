@@ -0,0 +1,51 @@
+package occupancy
+
+// Options holds the sensor model parameters in probability.
+type Options struct {
+	// Probability of the voxel being occupied when the ray hits the voxel.
+	ProbHit float32
+	// Probability of the voxel being occupied when the ray passes through the voxel.
+	ProbMiss float32
+	// Occupancy probability is clamped in [ClampMin, ClampMax]
+	// to keep the map updatable.
+	ClampMin, ClampMax float32
+	// Voxel is occupied if the occupancy probability is larger than OccupiedThreshold.
+	OccupiedThreshold float32
+	// Rays longer than MaxRange are truncated and the end points are not treated as hit.
+	// Zero means unlimited.
+	MaxRange float32
+}
+
+type Option func(*Options)
+
+var defaultOptions = Options{
+	ProbHit:           0.7,
+	ProbMiss:          0.4,
+	ClampMin:          0.1192,
+	ClampMax:          0.971,
+	OccupiedThreshold: 0.5,
+}
+
+func WithProbHitMiss(hit, miss float32) Option {
+	return Option(func(o *Options) {
+		o.ProbHit, o.ProbMiss = hit, miss
+	})
+}
+
+func WithClamp(min, max float32) Option {
+	return Option(func(o *Options) {
+		o.ClampMin, o.ClampMax = min, max
+	})
+}
+
+func WithOccupiedThreshold(th float32) Option {
+	return Option(func(o *Options) {
+		o.OccupiedThreshold = th
+	})
+}
+
+func WithMaxRange(r float32) Option {
+	return Option(func(o *Options) {
+		o.MaxRange = r
+	})
+}
@@ -0,0 +1,388 @@
+package octree
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/seqsense/pcgol/mat"
+	"github.com/seqsense/pcgol/pc"
+	"github.com/seqsense/pcgol/pc/storage"
+)
+
+var (
+	ErrOutOfRange   = errors.New("point is out of the range of the tree")
+	ErrInvalidPoint = errors.New("point coordinate is not finite")
+)
+
+// Octree is a sparse point storage.
+// Points are stored in the leaf voxels whose edge length is LeafSize.
+// The tree is expanded when a point outside the root node is inserted.
+type Octree struct {
+	pc.Vec3RandomAccessor
+	Options
+
+	root        *node
+	rootMin     [3]int
+	rootDepth   int
+	leafSizeInv float32
+}
+
+type node struct {
+	children [8]*node
+	indice   []int
+	num      int
+}
+
+// Voxel is a leaf voxel of the tree.
+type Voxel struct {
+	Min, Max mat.Vec3
+	Indice   []int
+}
+
+// New creates Octree and inserts all points in ra.
+func New(ra pc.Vec3RandomAccessor, leafSize float32, opts ...Option) (*Octree, error) {
+	o := &Octree{
+		Vec3RandomAccessor: ra,
+		Options: Options{
+			LeafSize: leafSize,
+			MaxDepth: DefaultMaxDepth,
+		},
+		leafSizeInv: 1 / leafSize,
+	}
+	for _, opt := range opts {
+		opt(&o.Options)
+	}
+	for i := 0; i < ra.Len(); i++ {
+		if err := o.Insert(i); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+func (o *Octree) key(p mat.Vec3) ([3]int, error) {
+	var k [3]int
+	for i := range p {
+		f := math.Floor(float64(p[i] * o.leafSizeInv))
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return k, ErrInvalidPoint
+		}
+		k[i] = int(f)
+	}
+	return k, nil
+}
+
+func (o *Octree) contains(k [3]int) bool {
+	size := 1 << o.rootDepth
+	for i := range k {
+		if k[i] < o.rootMin[i] || k[i] >= o.rootMin[i]+size {
+			return false
+		}
+	}
+	return true
+}
+
+// Insert adds the point to the tree.
+// The point must be already stored in the base Vec3RandomAccessor.
+func (o *Octree) Insert(id int) error {
+	if id < 0 || id > o.Len()-1 {
+		return fmt.Errorf("%d does not correspond to any point in the storage", id)
+	}
+	k, err := o.key(o.Vec3At(id))
+	if err != nil {
+		return err
+	}
+	if o.root == nil {
+		o.root = &node{}
+		o.rootMin = k
+		o.rootDepth = 0
+	}
+	for !o.contains(k) {
+		if o.rootDepth >= o.MaxDepth {
+			return ErrOutOfRange
+		}
+		size := 1 << o.rootDepth
+		var ci int
+		for i := range k {
+			if k[i] < o.rootMin[i] {
+				o.rootMin[i] -= size
+				ci |= 1 << i
+			}
+		}
+		root := &node{num: o.root.num}
+		root.children[ci] = o.root
+		o.root = root
+		o.rootDepth++
+	}
+
+	n := o.root
+	min := o.rootMin
+	for depth := o.rootDepth; depth > 0; depth-- {
+		n.num++
+		ci, cmin := childIndex(k, min, depth)
+		if n.children[ci] == nil {
+			n.children[ci] = &node{}
+		}
+		n, min = n.children[ci], cmin
+	}
+	n.num++
+	n.indice = append(n.indice, id)
+	return nil
+}
+
+// DeletePoint removes the point from the tree.
+func (o *Octree) DeletePoint(id int) error {
+	if id < 0 || id > o.Len()-1 {
+		return fmt.Errorf("%d does not correspond to any point in the storage", id)
+	}
+	k, err := o.key(o.Vec3At(id))
+	if err != nil {
+		return err
+	}
+	if o.root == nil || !o.contains(k) {
+		return fmt.Errorf("%d is not in the tree", id)
+	}
+
+	path := make([]*node, 0, o.rootDepth+1)
+	n := o.root
+	min := o.rootMin
+	for depth := o.rootDepth; depth > 0; depth-- {
+		path = append(path, n)
+		ci, cmin := childIndex(k, min, depth)
+		if n.children[ci] == nil {
+			return fmt.Errorf("%d is not in the tree", id)
+		}
+		n, min = n.children[ci], cmin
+	}
+	path = append(path, n)
+
+	found := -1
+	for i, idx := range n.indice {
+		if idx == id {
+			found = i
+			break
+		}
+	}
+	if found < 0 {
+		return fmt.Errorf("%d is not in the tree", id)
+	}
+	n.indice = append(n.indice[:found], n.indice[found+1:]...)
+
+	for i := len(path) - 1; i >= 0; i-- {
+		path[i].num--
+		if path[i].num > 0 || i == 0 {
+			continue
+		}
+		parent := path[i-1]
+		for ci, c := range parent.children {
+			if c == path[i] {
+				parent.children[ci] = nil
+			}
+		}
+	}
+	if o.root.num == 0 {
+		o.root = nil
+	}
+	return nil
+}
+
+func childIndex(k, min [3]int, depth int) (int, [3]int) {
+	half := 1 << (depth - 1)
+	var ci int
+	for i := range k {
+		if k[i] >= min[i]+half {
+			ci |= 1 << i
+			min[i] += half
+		}
+	}
+	return ci, min
+}
+
+func childMin(min [3]int, depth int, ci int) [3]int {
+	half := 1 << (depth - 1)
+	for i := range min {
+		if ci&(1<<i) != 0 {
+			min[i] += half
+		}
+	}
+	return min
+}
+
+func (o *Octree) bounds(min [3]int, depth int) (mat.Vec3, mat.Vec3) {
+	size := float32(int(1)<<depth) * o.LeafSize
+	bMin := mat.Vec3{
+		float32(min[0]) * o.LeafSize,
+		float32(min[1]) * o.LeafSize,
+		float32(min[2]) * o.LeafSize,
+	}
+	return bMin, bMin.Add(mat.Vec3{size, size, size})
+}
+
+func (o *Octree) boxDistSq(min [3]int, depth int, p mat.Vec3) float32 {
+	bMin, bMax := o.bounds(min, depth)
+	var dsq float32
+	for i := range p {
+		if d := bMin[i] - p[i]; d > 0 {
+			dsq += d * d
+		} else if d := p[i] - bMax[i]; d > 0 {
+			dsq += d * d
+		}
+	}
+	return dsq
+}
+
+func (o *Octree) Nearest(p mat.Vec3, maxRange float32) storage.Neighbor {
+	nn := storage.Neighbor{ID: -1, DistSq: maxRange * maxRange}
+	if o.root != nil {
+		o.nearestImpl(o.root, o.rootMin, o.rootDepth, p, &nn)
+	}
+	return nn
+}
+
+type child struct {
+	n      *node
+	min    [3]int
+	distSq float32
+}
+
+func (o *Octree) nearestImpl(n *node, min [3]int, depth int, p mat.Vec3, nn *storage.Neighbor) {
+	if depth == 0 {
+		for _, id := range n.indice {
+			if dsq := o.Vec3At(id).Sub(p).NormSq(); dsq < nn.DistSq {
+				nn.ID, nn.DistSq = id, dsq
+			}
+		}
+		return
+	}
+
+	var children [8]child
+	var nChildren int
+	for ci, c := range n.children {
+		if c == nil {
+			continue
+		}
+		cmin := childMin(min, depth, ci)
+		dsq := o.boxDistSq(cmin, depth-1, p)
+		if dsq >= nn.DistSq {
+			continue
+		}
+		// Insertion sort by the distance to the child voxel.
+		j := nChildren
+		for ; j > 0 && children[j-1].distSq > dsq; j-- {
+			children[j] = children[j-1]
+		}
+		children[j] = child{n: c, min: cmin, distSq: dsq}
+		nChildren++
+	}
+	for _, c := range children[:nChildren] {
+		if c.distSq >= nn.DistSq {
+			break
+		}
+		o.nearestImpl(c.n, c.min, depth-1, p, nn)
+	}
+}
+
+func (o *Octree) Range(p mat.Vec3, maxRange float32) []storage.Neighbor {
+	neighbors := []storage.Neighbor{}
+	if o.root != nil {
+		o.rangeImpl(o.root, o.rootMin, o.rootDepth, p, maxRange*maxRange, &neighbors)
+	}
+	sort.Sort(neighborSorter(neighbors))
+	return neighbors
+}
+
+func (o *Octree) rangeImpl(n *node, min [3]int, depth int, p mat.Vec3, maxRangeSq float32, neighbors *[]storage.Neighbor) {
+	if depth == 0 {
+		for _, id := range n.indice {
+			if dsq := o.Vec3At(id).Sub(p).NormSq(); dsq < maxRangeSq {
+				*neighbors = append(*neighbors, storage.Neighbor{ID: id, DistSq: dsq})
+			}
+		}
+		return
+	}
+	for ci, c := range n.children {
+		if c == nil {
+			continue
+		}
+		cmin := childMin(min, depth, ci)
+		if o.boxDistSq(cmin, depth-1, p) >= maxRangeSq {
+			continue
+		}
+		o.rangeImpl(c, cmin, depth-1, p, maxRangeSq, neighbors)
+	}
+}
+
+// Voxels calls fn for each leaf voxel which has points.
+// Iteration is stopped if fn returns false.
+func (o *Octree) Voxels(fn func(v Voxel) bool) {
+	if o.root != nil {
+		o.voxelsImpl(o.root, o.rootMin, o.rootDepth, fn)
+	}
+}
+
+func (o *Octree) voxelsImpl(n *node, min [3]int, depth int, fn func(v Voxel) bool) bool {
+	if depth == 0 {
+		bMin, bMax := o.bounds(min, 0)
+		return fn(Voxel{Min: bMin, Max: bMax, Indice: n.indice})
+	}
+	for ci, c := range n.children {
+		if c == nil {
+			continue
+		}
+		if !o.voxelsImpl(c, childMin(min, depth, ci), depth-1, fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// Box returns indice of the points inside the axis aligned bounding box.
+// Points on the boundary are included.
+func (o *Octree) Box(min, max mat.Vec3) []int {
+	out := []int{}
+	if o.root != nil {
+		o.boxImpl(o.root, o.rootMin, o.rootDepth, min, max, &out)
+	}
+	return out
+}
+
+func (o *Octree) boxImpl(n *node, nMin [3]int, depth int, min, max mat.Vec3, out *[]int) {
+	bMin, bMax := o.bounds(nMin, depth)
+	for i := range min {
+		if bMax[i] < min[i] || max[i] < bMin[i] {
+			return
+		}
+	}
+	if depth == 0 {
+		for _, id := range n.indice {
+			p := o.Vec3At(id)
+			if min[0] <= p[0] && p[0] <= max[0] &&
+				min[1] <= p[1] && p[1] <= max[1] &&
+				min[2] <= p[2] && p[2] <= max[2] {
+				*out = append(*out, id)
+			}
+		}
+		return
+	}
+	for ci, c := range n.children {
+		if c != nil {
+			o.boxImpl(c, childMin(nMin, depth, ci), depth-1, min, max, out)
+		}
+	}
+}
+
+type neighborSorter []storage.Neighbor
+
+func (ns neighborSorter) Len() int {
+	return len(ns)
+}
+
+func (ns neighborSorter) Swap(i, j int) {
+	ns[i], ns[j] = ns[j], ns[i]
+}
+
+func (ns neighborSorter) Less(i, j int) bool {
+	return ns[i].DistSq < ns[j].DistSq
+}
@@ -0,0 +1,218 @@
+package octree
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/seqsense/pcgol/mat"
+	"github.com/seqsense/pcgol/pc"
+	"github.com/seqsense/pcgol/pc/internal/pctest"
+	"github.com/seqsense/pcgol/pc/storage"
+	"github.com/seqsense/pcgol/pc/storage/storagetest"
+)
+
+var _ storage.Search = &Octree{} // Octree must implement storage.Search
+
+func naiveRange(ra pc.Vec3RandomAccessor, deleted map[int]bool, p mat.Vec3, maxRange float32) []storage.Neighbor {
+	neighbors := []storage.Neighbor{}
+	for i := 0; i < ra.Len(); i++ {
+		if deleted[i] {
+			continue
+		}
+		if dsq := ra.Vec3At(i).Sub(p).NormSq(); dsq < maxRange*maxRange {
+			neighbors = append(neighbors, storage.Neighbor{ID: i, DistSq: dsq})
+		}
+	}
+	sortNeighbors(neighbors)
+	return neighbors
+}
+
+func sortNeighbors(ns []storage.Neighbor) {
+	sort.Slice(ns, func(i, j int) bool {
+		if ns[i].DistSq == ns[j].DistSq {
+			return ns[i].ID < ns[j].ID
+		}
+		return ns[i].DistSq < ns[j].DistSq
+	})
+}
+
+func testSearch(t *testing.T, o *Octree, ra pc.Vec3RandomAccessor, deleted map[int]bool, width float32) {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		p := mat.Vec3{
+			rand.Float32()*width - width/2,
+			rand.Float32()*width - width/2,
+			rand.Float32()*width - width/2,
+		}
+		maxRange := rand.Float32() * width / 2
+
+		expected := naiveRange(ra, deleted, p, maxRange)
+		neighbors := o.Range(p, maxRange)
+		for j := 1; j < len(neighbors); j++ {
+			if neighbors[j].DistSq < neighbors[j-1].DistSq {
+				t.Fatal("Neighbors are not sorted")
+			}
+		}
+		sortNeighbors(neighbors)
+		if !reflect.DeepEqual(expected, neighbors) {
+			t.Fatalf("Range: Expected %v, got %v", expected, neighbors)
+		}
+
+		nn := o.Nearest(p, maxRange)
+		if len(expected) == 0 {
+			if nn.ID != -1 || nn.DistSq != maxRange*maxRange {
+				t.Fatalf("Nearest: Expected no neighbor, got %v", nn)
+			}
+		} else if nn.DistSq != expected[0].DistSq {
+			t.Fatalf("Nearest: Expected %v, got %v", expected[0], nn)
+		}
+	}
+}
+
+func TestOctree(t *testing.T) {
+	const width = 10.0
+
+	ra := pctest.RandomCloud(1000, -width/2, width/2)
+	o, err := New(ra, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testSearch(t, o, ra, nil, width)
+
+	t.Run("DeletePoint", func(t *testing.T) {
+		deleted := make(map[int]bool)
+		ids := rand.Perm(ra.Len())[:500]
+		for _, id := range ids {
+			if err := o.DeletePoint(id); err != nil {
+				t.Fatal(err)
+			}
+			deleted[id] = true
+		}
+		testSearch(t, o, ra, deleted, width)
+
+		if err := o.DeletePoint(ids[0]); err == nil {
+			t.Error("Expected error on deleting the point twice")
+		}
+
+		for id := range deleted {
+			if err := o.Insert(id); err != nil {
+				t.Fatal(err)
+			}
+		}
+		testSearch(t, o, ra, nil, width)
+	})
+	t.Run("DeleteAll", func(t *testing.T) {
+		o, err := New(ra[:10], 0.5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 10; i++ {
+			if err := o.DeletePoint(i); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if o.root != nil {
+			t.Error("Expected empty tree")
+		}
+		if nn := o.Nearest(mat.Vec3{}, width); nn.ID != -1 {
+			t.Errorf("Expected no neighbor, got %v", nn)
+		}
+	})
+}
+
+func TestOctree_Expand(t *testing.T) {
+	ra := pc.Vec3Slice{
+		{0.1, 0.1, 0.1},
+		{-100, 50, 3},
+		{1000, -1000, 0},
+	}
+	o, err := New(ra, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range ra {
+		if nn := o.Nearest(p, 1); nn.ID != i || nn.DistSq != 0 {
+			t.Errorf("Expected %d, got %v", i, nn)
+		}
+	}
+
+	if _, err := New(ra, 1, WithMaxDepth(8)); err != ErrOutOfRange {
+		t.Errorf("Expected error: %v, got: %v", ErrOutOfRange, err)
+	}
+}
+
+func TestOctree_Voxels(t *testing.T) {
+	ra := pc.Vec3Slice{
+		{0.1, 0.1, 0.1},
+		{0.2, 0.3, 0.4},
+		{1.5, 0.1, 0.1},
+		{-0.5, -0.5, 2.5},
+	}
+	o, err := New(ra, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	voxels := map[mat.Vec3][]int{}
+	o.Voxels(func(v Voxel) bool {
+		if !v.Max.Sub(v.Min).Equal(mat.Vec3{1, 1, 1}) {
+			t.Errorf("Wrong voxel size: %v - %v", v.Min, v.Max)
+		}
+		voxels[v.Min] = append([]int{}, v.Indice...)
+		return true
+	})
+	expected := map[mat.Vec3][]int{
+		{0, 0, 0}:   {0, 1},
+		{1, 0, 0}:   {2},
+		{-1, -1, 2}: {3},
+	}
+	if !reflect.DeepEqual(expected, voxels) {
+		t.Errorf("Expected %v, got %v", expected, voxels)
+	}
+
+	var n int
+	o.Voxels(func(v Voxel) bool {
+		n++
+		return false
+	})
+	if n != 1 {
+		t.Errorf("Expected iteration to be stopped, called %d times", n)
+	}
+}
+
+func TestOctree_Box(t *testing.T) {
+	const width = 10.0
+
+	ra := pctest.RandomCloud(1000, -width/2, width/2)
+	o, err := New(ra, 0.3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	min, max := mat.Vec3{-1, -2, -3}, mat.Vec3{3, 2, 1}
+
+	var expected []int
+	for i, p := range ra {
+		if min[0] <= p[0] && p[0] <= max[0] &&
+			min[1] <= p[1] && p[1] <= max[1] &&
+			min[2] <= p[2] && p[2] <= max[2] {
+			expected = append(expected, i)
+		}
+	}
+	ids := o.Box(min, max)
+	sort.Ints(ids)
+	if !reflect.DeepEqual(expected, ids) {
+		t.Errorf("Expected %v, got %v", expected, ids)
+	}
+}
+
+func TestOctree_conformance(t *testing.T) {
+	storagetest.TestSearch(t, func(t *testing.T, ra pc.Vec3RandomAccessor) storage.Search {
+		o, err := New(ra, 0.3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return o
+	})
+}
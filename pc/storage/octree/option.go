@@ -0,0 +1,20 @@
+package octree
+
+// DefaultMaxDepth is the default maximum depth of the tree.
+// Root node can contain 2^DefaultMaxDepth leaves along each axis.
+const DefaultMaxDepth = 21
+
+type Options struct {
+	LeafSize float32
+	MaxDepth int
+}
+
+type Option func(*Options)
+
+// WithMaxDepth sets the maximum depth of the tree.
+// Points farther than LeafSize * 2^MaxDepth from the others can't be inserted.
+func WithMaxDepth(d int) Option {
+	return Option(func(o *Options) {
+		o.MaxDepth = d
+	})
+}
@@ -15,3 +15,10 @@ type Search interface {
 	Nearest(p mat.Vec3, maxRange float32) Neighbor
 	Range(p mat.Vec3, maxRange float32) []Neighbor
 }
+
+// KSearch is a Search which also supports k-nearest neighbor search.
+type KSearch interface {
+	Search
+	// KNearest returns at most k nearest neighbors in maxRange sorted by the distance.
+	KNearest(p mat.Vec3, k int, maxRange float32) []Neighbor
+}
@@ -0,0 +1,193 @@
+// Package storagetest provides conformance tests of the storage.Search implementations.
+package storagetest
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/seqsense/pcgol/mat"
+	"github.com/seqsense/pcgol/pc"
+	"github.com/seqsense/pcgol/pc/internal/pctest"
+	"github.com/seqsense/pcgol/pc/storage"
+	"github.com/seqsense/pcgol/pc/storage/bruteforce"
+)
+
+// Extent is the range of the test point coordinates.
+// All test points are inside [-Extent, Extent] along each axis.
+const Extent = 5.0
+
+// NewSearchFunc creates the storage which contains all points in ra.
+type NewSearchFunc func(t *testing.T, ra pc.Vec3RandomAccessor) storage.Search
+
+// TestSearch runs the conformance tests of storage.Search.
+// KNearest and Inside are also tested if the storage implements
+// storage.KSearch and storage.VolumeSearch.
+func TestSearch(t *testing.T, newSearch NewSearchFunc) {
+	clouds := map[string]pc.Vec3Slice{
+		"Random":     pctest.RandomCloud(1000, -Extent, Extent),
+		"Duplicated": duplicatedCloud(),
+		"Single":     {{1, 2, 3}},
+		"Empty":      {},
+	}
+	for name, ra := range clouds {
+		ra := ra
+		t.Run(name, func(t *testing.T) {
+			s := newSearch(t, ra)
+			ref := bruteforce.New(ra)
+			queries := pctest.RandomCloud(100, -Extent*2, Extent*2)
+			queries = append(queries, ra...)
+
+			t.Run("Nearest", func(t *testing.T) {
+				for _, p := range queries {
+					for _, maxRange := range []float32{0.5, 2, Extent * 4} {
+						testNearest(t, s, ref, p, maxRange)
+					}
+				}
+			})
+			t.Run("Range", func(t *testing.T) {
+				for _, p := range queries {
+					for _, maxRange := range []float32{0.5, 2} {
+						expected := ref.Range(p, maxRange)
+						neighbors := s.Range(p, maxRange)
+						if !sortedByDistance(neighbors) {
+							t.Fatalf("%v %f: Neighbors are not sorted: %v", p, maxRange, neighbors)
+						}
+						sort.Sort(neighborIDSorter(expected))
+						sort.Sort(neighborIDSorter(neighbors))
+						if !reflect.DeepEqual(expected, neighbors) {
+							t.Fatalf("%v %f: Expected %v, got %v", p, maxRange, expected, neighbors)
+						}
+					}
+				}
+			})
+			if ks, ok := s.(storage.KSearch); ok {
+				t.Run("KNearest", func(t *testing.T) {
+					for _, p := range queries {
+						for _, k := range []int{0, 1, 5} {
+							expected := ref.KNearest(p, k, 2)
+							neighbors := ks.KNearest(p, k, 2)
+							if !sortedByDistance(neighbors) {
+								t.Fatalf("%v %d: Neighbors are not sorted: %v", p, k, neighbors)
+							}
+							if !sameDistances(ra, p, expected, neighbors) {
+								t.Fatalf("%v %d: Expected %v, got %v", p, k, expected, neighbors)
+							}
+						}
+					}
+				})
+			}
+			if vs, ok := s.(storage.VolumeSearch); ok {
+				t.Run("Inside", func(t *testing.T) {
+					volumes := []storage.Volume{
+						storage.AABB{Min: mat.Vec3{-1, -2, -3}, Max: mat.Vec3{3, 2, 1}},
+						storage.AABB{Min: mat.Vec3{-Extent, -Extent, -Extent}, Max: mat.Vec3{Extent, Extent, Extent}},
+						storage.NewOrientedBox(mat.Rotate(1, 2, 3, 0.5), mat.Vec3{4, 3, 2}),
+						storage.NewPredicate(mat.Vec3{-2, -2, -2}, mat.Vec3{2, 2, 2}, func(p mat.Vec3) bool {
+							return p.NormSq() < 4
+						}),
+					}
+					for i, v := range volumes {
+						expected := ref.Inside(v)
+						if ids := vs.Inside(v); !reflect.DeepEqual(expected, ids) {
+							t.Fatalf("Volume %d: Expected %v, got %v", i, expected, ids)
+						}
+					}
+				})
+			}
+		})
+	}
+}
+
+// TestApproxNearest tests approximated Nearest search.
+// Returned neighbor must be the exact nearest neighbor or closer than minDist.
+func TestApproxNearest(t *testing.T, newSearch NewSearchFunc, minDist float32) {
+	ra := pctest.RandomCloud(1000, -Extent, Extent)
+	s := newSearch(t, ra)
+	ref := bruteforce.New(ra)
+	for _, p := range pctest.RandomCloud(1000, -Extent, Extent) {
+		expected := ref.Nearest(p, Extent)
+		nn := s.Nearest(p, Extent)
+		if nn.ID < 0 {
+			if expected.ID >= 0 {
+				t.Fatalf("%v: Expected %v, got %v", p, expected, nn)
+			}
+			continue
+		}
+		if dsq := ra[nn.ID].Sub(p).NormSq(); dsq != nn.DistSq {
+			t.Fatalf("%v: Wrong distance of %v, expected %f", p, nn, dsq)
+		}
+		if nn.DistSq != expected.DistSq && nn.DistSq >= minDist*minDist {
+			t.Fatalf("%v: Expected %v or distance < %f, got %v", p, expected, minDist, nn)
+		}
+	}
+}
+
+func testNearest(t *testing.T, s storage.Search, ref *bruteforce.Search, p mat.Vec3, maxRange float32) {
+	t.Helper()
+	expected := ref.Nearest(p, maxRange)
+	nn := s.Nearest(p, maxRange)
+	if expected.ID < 0 {
+		if nn != expected {
+			t.Fatalf("%v %f: Expected %v, got %v", p, maxRange, expected, nn)
+		}
+		return
+	}
+	// Points with the same distance may be returned.
+	if nn.ID < 0 || nn.DistSq != expected.DistSq || s.Vec3At(nn.ID).Sub(p).NormSq() != nn.DistSq {
+		t.Fatalf("%v %f: Expected %v, got %v", p, maxRange, expected, nn)
+	}
+}
+
+func sortedByDistance(ns []storage.Neighbor) bool {
+	for i := 1; i < len(ns); i++ {
+		if ns[i].DistSq < ns[i-1].DistSq {
+			return false
+		}
+	}
+	return true
+}
+
+// sameDistances checks that two neighbor lists are the same except
+// the order of the points with the same distance.
+func sameDistances(ra pc.Vec3RandomAccessor, p mat.Vec3, expected, neighbors []storage.Neighbor) bool {
+	if len(expected) != len(neighbors) {
+		return false
+	}
+	ids := make(map[int]bool)
+	for i := range expected {
+		n := neighbors[i]
+		if n.DistSq != expected[i].DistSq || ra.Vec3At(n.ID).Sub(p).NormSq() != n.DistSq || ids[n.ID] {
+			return false
+		}
+		ids[n.ID] = true
+	}
+	return true
+}
+
+func duplicatedCloud() pc.Vec3Slice {
+	var ra pc.Vec3Slice
+	for i := 0; i < 4; i++ {
+		for _, p := range []mat.Vec3{{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {-1, 1, 1}} {
+			ra = append(ra, p)
+		}
+	}
+	return ra
+}
+
+type neighborIDSorter []storage.Neighbor
+
+func (ns neighborIDSorter) Len() int {
+	return len(ns)
+}
+
+func (ns neighborIDSorter) Swap(i, j int) {
+	ns[i], ns[j] = ns[j], ns[i]
+}
+
+func (ns neighborIDSorter) Less(i, j int) bool {
+	if ns[i].DistSq == ns[j].DistSq {
+		return ns[i].ID < ns[j].ID
+	}
+	return ns[i].DistSq < ns[j].DistSq
+}
@@ -0,0 +1,189 @@
+package storage
+
+import (
+	"github.com/seqsense/pcgol/mat"
+)
+
+// Volume is a region of the space used for the spatial queries.
+type Volume interface {
+	// Bounds returns axis aligned bounding box of the volume.
+	Bounds() (min, max mat.Vec3)
+	// Contains returns true if the point is inside the volume.
+	Contains(p mat.Vec3) bool
+	// IntersectsBox returns false if the axis aligned box is completely outside the volume.
+	// It may return true for the box outside the volume, and is used to prune the search.
+	IntersectsBox(min, max mat.Vec3) bool
+}
+
+// VolumeSearch is a storage which supports Volume queries.
+type VolumeSearch interface {
+	// Inside returns indice of the points inside the volume in ascending order.
+	Inside(v Volume) []int
+}
+
+// AABB is an axis aligned bounding box.
+// Points on the boundary are inside the box.
+type AABB struct {
+	Min, Max mat.Vec3
+}
+
+func (b AABB) Bounds() (min, max mat.Vec3) {
+	return b.Min, b.Max
+}
+
+func (b AABB) Contains(p mat.Vec3) bool {
+	return b.Min[0] <= p[0] && p[0] <= b.Max[0] &&
+		b.Min[1] <= p[1] && p[1] <= b.Max[1] &&
+		b.Min[2] <= p[2] && p[2] <= b.Max[2]
+}
+
+func (b AABB) IntersectsBox(min, max mat.Vec3) bool {
+	return b.Min[0] <= max[0] && min[0] <= b.Max[0] &&
+		b.Min[1] <= max[1] && min[1] <= b.Max[1] &&
+		b.Min[2] <= max[2] && min[2] <= b.Max[2]
+}
+
+// plane represents the half space n.p + d >= 0.
+type plane struct {
+	n mat.Vec3
+	d float32
+}
+
+// convexVolume is a volume surrounded by the planes.
+type convexVolume struct {
+	planes   []plane
+	min, max mat.Vec3
+}
+
+func (v *convexVolume) Bounds() (min, max mat.Vec3) {
+	return v.min, v.max
+}
+
+func (v *convexVolume) Contains(p mat.Vec3) bool {
+	for _, pl := range v.planes {
+		if pl.n.Dot(p)+pl.d < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (v *convexVolume) IntersectsBox(min, max mat.Vec3) bool {
+	if !(AABB{Min: v.min, Max: v.max}).IntersectsBox(min, max) {
+		return false
+	}
+	for _, pl := range v.planes {
+		// Corner of the box farthest along the plane normal.
+		var c mat.Vec3
+		for i := range c {
+			if pl.n[i] >= 0 {
+				c[i] = max[i]
+			} else {
+				c[i] = min[i]
+			}
+		}
+		if pl.n.Dot(c)+pl.d < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func boundsOf(pp []mat.Vec3) (min, max mat.Vec3) {
+	min, max = pp[0], pp[0]
+	for _, p := range pp[1:] {
+		for i := range p {
+			if p[i] < min[i] {
+				min[i] = p[i]
+			}
+			if p[i] > max[i] {
+				max[i] = p[i]
+			}
+		}
+	}
+	return min, max
+}
+
+// NewOrientedBox creates a box volume.
+// The box is centered at the origin of the pose, and its edges are
+// aligned to the axes of the pose. size is the edge lengths of the box.
+func NewOrientedBox(pose mat.Mat4, size mat.Vec3) Volume {
+	h := size.Mul(0.5)
+	v := &convexVolume{}
+	center := pose.TransformAffine(mat.Vec3{})
+	for i := 0; i < 3; i++ {
+		axis := mat.Vec3{pose[4*i+0], pose[4*i+1], pose[4*i+2]}.Normalized()
+		c := axis.Dot(center)
+		v.planes = append(v.planes,
+			plane{n: axis, d: h[i] - c},
+			plane{n: axis.Mul(-1), d: h[i] + c},
+		)
+	}
+	corners := make([]mat.Vec3, 0, 8)
+	for _, x := range []float32{-h[0], h[0]} {
+		for _, y := range []float32{-h[1], h[1]} {
+			for _, z := range []float32{-h[2], h[2]} {
+				corners = append(corners, pose.TransformAffine(mat.Vec3{x, y, z}))
+			}
+		}
+	}
+	v.min, v.max = boundsOf(corners)
+	return v
+}
+
+// NewFrustum creates a view frustum volume from the matrix which transforms
+// the point to the clip coordinate like mat.Perspective(...).Mul(view).
+// Points inside -w <= x, y, z <= w in the clip coordinate are inside the volume.
+func NewFrustum(m mat.Mat4) Volume {
+	row := func(i int) [4]float32 {
+		return [4]float32{m[4*0+i], m[4*1+i], m[4*2+i], m[4*3+i]}
+	}
+	r3 := row(3)
+	v := &convexVolume{}
+	for i := 0; i < 3; i++ {
+		r := row(i)
+		for _, s := range []float32{1, -1} {
+			pl := plane{
+				n: mat.Vec3{r3[0] + s*r[0], r3[1] + s*r[1], r3[2] + s*r[2]},
+				d: r3[3] + s*r[3],
+			}
+			norm := pl.n.Norm()
+			pl.n, pl.d = pl.n.Mul(1/norm), pl.d/norm
+			v.planes = append(v.planes, pl)
+		}
+	}
+	inv := m.Inv()
+	corners := make([]mat.Vec3, 0, 8)
+	for _, x := range []float32{-1, 1} {
+		for _, y := range []float32{-1, 1} {
+			for _, z := range []float32{-1, 1} {
+				corners = append(corners, inv.Transform(mat.Vec3{x, y, z}))
+			}
+		}
+	}
+	v.min, v.max = boundsOf(corners)
+	return v
+}
+
+type predicateVolume struct {
+	min, max mat.Vec3
+	fn       func(p mat.Vec3) bool
+}
+
+// NewPredicate creates a volume defined by the function.
+// fn must return false for the points outside the box min-max.
+func NewPredicate(min, max mat.Vec3, fn func(p mat.Vec3) bool) Volume {
+	return &predicateVolume{min: min, max: max, fn: fn}
+}
+
+func (v *predicateVolume) Bounds() (min, max mat.Vec3) {
+	return v.min, v.max
+}
+
+func (v *predicateVolume) Contains(p mat.Vec3) bool {
+	return (AABB{Min: v.min, Max: v.max}).Contains(p) && v.fn(p)
+}
+
+func (v *predicateVolume) IntersectsBox(min, max mat.Vec3) bool {
+	return (AABB{Min: v.min, Max: v.max}).IntersectsBox(min, max)
+}
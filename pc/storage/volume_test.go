@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"math"
+	"testing"
+
+	"github.com/seqsense/pcgol/mat"
+)
+
+func TestVolume(t *testing.T) {
+	testCases := map[string]struct {
+		v               Volume
+		in, out         []mat.Vec3
+		min, max        mat.Vec3
+		boxIn, boxOut   [][2]mat.Vec3
+		boundsTolerance float32
+	}{
+		"AABB": {
+			v:      AABB{Min: mat.Vec3{-1, -2, -3}, Max: mat.Vec3{1, 2, 3}},
+			in:     []mat.Vec3{{0, 0, 0}, {1, 2, 3}, {-1, 1, -2}},
+			out:    []mat.Vec3{{1.1, 0, 0}, {0, -2.1, 0}, {0, 0, 4}},
+			min:    mat.Vec3{-1, -2, -3},
+			max:    mat.Vec3{1, 2, 3},
+			boxIn:  [][2]mat.Vec3{{{0.5, 0.5, 0.5}, {5, 5, 5}}},
+			boxOut: [][2]mat.Vec3{{{1.5, 0, 0}, {5, 5, 5}}},
+		},
+		"OrientedBox": {
+			v: NewOrientedBox(
+				mat.Translate(1, 0, 0).Mul(mat.Rotate(0, 0, 1, math.Pi/4)),
+				mat.Vec3{2, 1, 1},
+			),
+			in:     []mat.Vec3{{1, 0, 0}, {1.6, 0.6, 0.4}, {0.4, -0.6, -0.4}},
+			out:    []mat.Vec3{{1.6, -0.6, 0}, {1, 0, 0.6}, {2, 0, 0}},
+			min:    mat.Vec3{1 - 0.75*math.Sqrt2, -0.75 * math.Sqrt2, -0.5},
+			max:    mat.Vec3{1 + 0.75*math.Sqrt2, 0.75 * math.Sqrt2, 0.5},
+			boxIn:  [][2]mat.Vec3{{{1.5, 0.5, -1}, {1.6, 0.6, 1}}},
+			boxOut: [][2]mat.Vec3{{{1.5, -0.7, -1}, {1.7, -0.5, 1}}},
+
+			boundsTolerance: 1e-5,
+		},
+		"Frustum": {
+			v:      NewFrustum(mat.Perspective(math.Pi/2, 1, 1, 10)),
+			in:     []mat.Vec3{{0, 0, -5}, {4, 0, -5}, {0, -1, -1.1}, {0, 0, -9.9}},
+			out:    []mat.Vec3{{0, 0, 5}, {6, 0, -5}, {0, 0, -0.5}, {0, 0, -11}},
+			min:    mat.Vec3{-10, -10, -10},
+			max:    mat.Vec3{10, 10, -1},
+			boxIn:  [][2]mat.Vec3{{{-1, -1, -3}, {1, 1, -2}}},
+			boxOut: [][2]mat.Vec3{{{5, -1, -3}, {6, 1, -2}}, {{-1, -1, 1}, {1, 1, 2}}},
+
+			boundsTolerance: 1e-4,
+		},
+		"Predicate": {
+			v: NewPredicate(mat.Vec3{-1, -1, -1}, mat.Vec3{1, 1, 1}, func(p mat.Vec3) bool {
+				return p.NormSq() <= 1
+			}),
+			in:     []mat.Vec3{{0, 0, 0}, {0.5, 0.5, 0.5}, {0, 0, -1}},
+			out:    []mat.Vec3{{0.8, 0.8, 0}, {2, 0, 0}},
+			min:    mat.Vec3{-1, -1, -1},
+			max:    mat.Vec3{1, 1, 1},
+			boxIn:  [][2]mat.Vec3{{{0, 0, 0}, {2, 2, 2}}},
+			boxOut: [][2]mat.Vec3{{{1.5, 0, 0}, {2, 2, 2}}},
+		},
+	}
+	for name, tt := range testCases {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			for _, p := range tt.in {
+				if !tt.v.Contains(p) {
+					t.Errorf("%v must be inside", p)
+				}
+			}
+			for _, p := range tt.out {
+				if tt.v.Contains(p) {
+					t.Errorf("%v must be outside", p)
+				}
+			}
+			min, max := tt.v.Bounds()
+			for i := range min {
+				if d := min[i] - tt.min[i]; d < -tt.boundsTolerance || tt.boundsTolerance < d {
+					t.Errorf("Expected min: %v, got: %v", tt.min, min)
+				}
+				if d := max[i] - tt.max[i]; d < -tt.boundsTolerance || tt.boundsTolerance < d {
+					t.Errorf("Expected max: %v, got: %v", tt.max, max)
+				}
+			}
+			for _, b := range tt.boxIn {
+				if !tt.v.IntersectsBox(b[0], b[1]) {
+					t.Errorf("Box %v must intersect", b)
+				}
+			}
+			for _, b := range tt.boxOut {
+				if tt.v.IntersectsBox(b[0], b[1]) {
+					t.Errorf("Box %v must not intersect", b)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package voxelgrid
+
+import (
+	"math"
+
+	"github.com/seqsense/pcgol/mat"
+)
+
+// DistanceField stores the Euclidean distance from each voxel center
+// to the nearest occupied voxel center.
+type DistanceField struct {
+	dist          []float32
+	size          [3]int
+	origin        mat.Vec3
+	resolution    float32
+	resolutionInv float32
+}
+
+// DistanceField calculates the exact Euclidean distance transform of the voxel grid
+// using Felzenszwalb-Huttenlocher algorithm.
+// Voxels containing any point are treated as occupied.
+// If no voxel is occupied, all distances are +Inf.
+func (v *VoxelGrid) DistanceField() *DistanceField {
+	n := v.Len()
+	df := &DistanceField{
+		dist:          make([]float32, n),
+		size:          v.size,
+		origin:        v.origin,
+		resolution:    v.resolution,
+		resolutionInv: v.resolutionInv,
+	}
+
+	// Squared distance in voxel unit.
+	sq := make([]float64, n)
+	for i, vx := range v.voxel {
+		if len(vx) == 0 {
+			sq[i] = math.Inf(1)
+		}
+	}
+
+	maxSize := v.size[0]
+	if v.size[1] > maxSize {
+		maxSize = v.size[1]
+	}
+	if v.size[2] > maxSize {
+		maxSize = v.size[2]
+	}
+	t := newEDT1D(maxSize)
+
+	stride := [3]int{1, v.size[0], v.size[0] * v.size[1]}
+	for axis := 0; axis < 3; axis++ {
+		a1, a2 := (axis+1)%3, (axis+2)%3
+		for j := 0; j < v.size[a1]; j++ {
+			for k := 0; k < v.size[a2]; k++ {
+				t.transform(sq, j*stride[a1]+k*stride[a2], stride[axis], v.size[axis])
+			}
+		}
+	}
+
+	for i, d := range sq {
+		df.dist[i] = float32(math.Sqrt(d)) * v.resolution
+	}
+	return df
+}
+
+// edt1D is a work buffer of one dimensional squared distance transform.
+type edt1D struct {
+	f []float64
+	d []float64
+	v []int
+	z []float64
+}
+
+func newEDT1D(n int) *edt1D {
+	return &edt1D{
+		f: make([]float64, n),
+		d: make([]float64, n),
+		v: make([]int, n),
+		z: make([]float64, n+1),
+	}
+}
+
+// transform calculates lower envelope of the parabolas rooted at the n samples
+// of data starting from offset and overwrites the data by the result.
+func (t *edt1D) transform(data []float64, offset, stride, n int) {
+	f := t.f[:n]
+	for i := range f {
+		f[i] = data[offset+i*stride]
+	}
+
+	k := -1
+	for q := 0; q < n; q++ {
+		if math.IsInf(f[q], 1) {
+			continue
+		}
+		for k >= 0 {
+			s := t.intersection(t.v[k], q)
+			if s > t.z[k] {
+				k++
+				t.v[k] = q
+				t.z[k] = s
+				break
+			}
+			k--
+		}
+		if k < 0 {
+			k = 0
+			t.v[0] = q
+			t.z[0] = math.Inf(-1)
+		}
+		t.z[k+1] = math.Inf(1)
+	}
+	if k < 0 {
+		// No finite sample.
+		return
+	}
+
+	j := 0
+	for q := 0; q < n; q++ {
+		for t.z[j+1] < float64(q) {
+			j++
+		}
+		dq := float64(q - t.v[j])
+		t.d[q] = dq*dq + f[t.v[j]]
+	}
+	for q := 0; q < n; q++ {
+		data[offset+q*stride] = t.d[q]
+	}
+}
+
+func (t *edt1D) intersection(p, q int) float64 {
+	fp, fq := float64(p), float64(q)
+	return ((t.f[q] + fq*fq) - (t.f[p] + fp*fp)) / (2*fq - 2*fp)
+}
+
+func (df *DistanceField) Resolution() float32 {
+	return df.resolution
+}
+
+// DistanceByPosInt returns the distance at the voxel center.
+func (df *DistanceField) DistanceByPosInt(p [3]int) (float32, bool) {
+	for i := range p {
+		if p[i] < 0 || p[i] >= df.size[i] {
+			return 0, false
+		}
+	}
+	return df.dist[p[0]+(p[1]+p[2]*df.size[1])*df.size[0]], true
+}
+
+// Distance returns the distance at p trilinearly interpolated from the adjacent voxel centers.
+// It returns false if p is out of the grid.
+func (df *DistanceField) Distance(p mat.Vec3) (float32, bool) {
+	d, _, ok := df.interpolate(p, false)
+	return d, ok
+}
+
+// Gradient returns the gradient of the trilinearly interpolated distance at p.
+// It returns false if p is out of the grid.
+func (df *DistanceField) Gradient(p mat.Vec3) (mat.Vec3, bool) {
+	_, g, ok := df.interpolate(p, true)
+	return g, ok
+}
+
+func (df *DistanceField) interpolate(p mat.Vec3, grad bool) (float32, mat.Vec3, bool) {
+	var (
+		i0, i1 [3]int
+		w      [3]float32
+	)
+	for i := 0; i < 3; i++ {
+		x := (p[i] - df.origin[i]) * df.resolutionInv
+		if x < -0.5 || x >= float32(df.size[i])-0.5 {
+			return 0, mat.Vec3{}, false
+		}
+		// Values outside of the outermost voxel centers are extrapolated as constant.
+		f := int(math.Floor(float64(x)))
+		switch {
+		case f < 0:
+			i0[i], i1[i], w[i] = 0, 0, 0
+		case f >= df.size[i]-1:
+			i0[i], i1[i], w[i] = f, f, 0
+		default:
+			i0[i], i1[i], w[i] = f, f+1, x-float32(f)
+		}
+	}
+
+	at := func(x, y, z int) float32 {
+		return df.dist[x+(y+z*df.size[1])*df.size[0]]
+	}
+	c000 := at(i0[0], i0[1], i0[2])
+	if math.IsInf(float64(c000), 1) {
+		return c000, mat.Vec3{}, true
+	}
+	c100 := at(i1[0], i0[1], i0[2])
+	c010 := at(i0[0], i1[1], i0[2])
+	c110 := at(i1[0], i1[1], i0[2])
+	c001 := at(i0[0], i0[1], i1[2])
+	c101 := at(i1[0], i0[1], i1[2])
+	c011 := at(i0[0], i1[1], i1[2])
+	c111 := at(i1[0], i1[1], i1[2])
+
+	lerp := func(a, b, t float32) float32 {
+		return a + (b-a)*t
+	}
+	c00 := lerp(c000, c100, w[0])
+	c10 := lerp(c010, c110, w[0])
+	c01 := lerp(c001, c101, w[0])
+	c11 := lerp(c011, c111, w[0])
+	c0 := lerp(c00, c10, w[1])
+	c1 := lerp(c01, c11, w[1])
+	d := lerp(c0, c1, w[2])
+	if !grad {
+		return d, mat.Vec3{}, true
+	}
+
+	var g mat.Vec3
+	if i0[2] != i1[2] {
+		g[2] = (c1 - c0) * df.resolutionInv
+	}
+	if i0[1] != i1[1] {
+		g[1] = lerp(c10-c00, c11-c01, w[2]) * df.resolutionInv
+	}
+	if i0[0] != i1[0] {
+		g[0] = lerp(
+			lerp(c100-c000, c110-c010, w[1]),
+			lerp(c101-c001, c111-c011, w[1]),
+			w[2],
+		) * df.resolutionInv
+	}
+	return d, g, true
+}
@@ -0,0 +1,147 @@
+package voxelgrid
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/seqsense/pcgol/mat"
+)
+
+func TestVoxelGrid_DistanceField(t *testing.T) {
+	const res = 0.1
+	origin := mat.Vec3{-1, 2, 0.5}
+
+	testCases := map[string]struct {
+		size     [3]int
+		occupied [][3]int
+	}{
+		"Single": {
+			size:     [3]int{8, 6, 5},
+			occupied: [][3]int{{3, 2, 1}},
+		},
+		"Corners": {
+			size:     [3]int{7, 9, 4},
+			occupied: [][3]int{{0, 0, 0}, {6, 8, 3}},
+		},
+		"Random": {
+			size: [3]int{12, 10, 9},
+			occupied: func() [][3]int {
+				var out [][3]int
+				for i := 0; i < 20; i++ {
+					out = append(out, [3]int{rand.Intn(12), rand.Intn(10), rand.Intn(9)})
+				}
+				return out
+			}(),
+		},
+		"Flat": {
+			size:     [3]int{10, 1, 10},
+			occupied: [][3]int{{2, 0, 7}, {8, 0, 1}},
+		},
+	}
+	for name, tt := range testCases {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			v := New(res, tt.size, origin)
+			for i, p := range tt.occupied {
+				a, ok := v.AddrByPosInt(p)
+				if !ok {
+					t.Fatalf("Invalid test case %v", p)
+				}
+				v.AddByAddr(a, i)
+			}
+			df := v.DistanceField()
+
+			for x := 0; x < tt.size[0]; x++ {
+				for y := 0; y < tt.size[1]; y++ {
+					for z := 0; z < tt.size[2]; z++ {
+						expected := math.Inf(1)
+						for _, o := range tt.occupied {
+							dx, dy, dz := float64(x-o[0]), float64(y-o[1]), float64(z-o[2])
+							if d := math.Sqrt(dx*dx+dy*dy+dz*dz) * res; d < expected {
+								expected = d
+							}
+						}
+						d, ok := df.DistanceByPosInt([3]int{x, y, z})
+						if !ok {
+							t.Fatal("Voxel in the grid must have distance")
+						}
+						if math.Abs(float64(d)-expected) > 0.0001 {
+							t.Errorf("Expected distance at %v: %f, got %f", [3]int{x, y, z}, expected, d)
+						}
+						// Query at the voxel center must be same as the voxel value.
+						p := origin.Add(mat.Vec3{float32(x), float32(y), float32(z)}.Mul(res))
+						if d2, ok := df.Distance(p); !ok || math.Abs(float64(d2-d)) > 0.0001 {
+							t.Errorf("Expected distance at %v: %f, got %f", p, d, d2)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDistanceField_Gradient(t *testing.T) {
+	v := New(0.1, [3]int{10, 10, 10}, mat.Vec3{})
+	a, _ := v.AddrByPosInt([3]int{2, 3, 4})
+	v.AddByAddr(a, 0)
+	df := v.DistanceField()
+
+	for _, p := range []mat.Vec3{
+		{0.72, 0.55, 0.61},
+		{0.13, 0.81, 0.27},
+		{0.51, 0.08, 0.83},
+	} {
+		g, ok := df.Gradient(p)
+		if !ok {
+			t.Fatalf("Gradient at %v must be available", p)
+		}
+		const eps = 0.001
+		for i := 0; i < 3; i++ {
+			p0, p1 := p, p
+			p0[i] -= eps
+			p1[i] += eps
+			d0, _ := df.Distance(p0)
+			d1, _ := df.Distance(p1)
+			expected := (d1 - d0) / (2 * eps)
+			if diff := g[i] - expected; diff < -0.01 || 0.01 < diff {
+				t.Errorf("Expected gradient[%d] at %v: %f, got %f", i, p, expected, g[i])
+			}
+		}
+		// Gradient points away from the occupied voxel.
+		dir := p.Sub(mat.Vec3{0.2, 0.3, 0.4})
+		if g.Dot(dir) <= 0 {
+			t.Errorf("Gradient %v at %v must point away from the occupied voxel", g, p)
+		}
+	}
+
+	if _, ok := df.Gradient(mat.Vec3{-0.1, 0, 0}); ok {
+		t.Error("Gradient out of the grid must not be available")
+	}
+	if _, ok := df.Distance(mat.Vec3{0, 0, 0.96}); ok {
+		t.Error("Distance out of the grid must not be available")
+	}
+}
+
+func TestDistanceField_empty(t *testing.T) {
+	df := New(0.1, [3]int{4, 4, 4}, mat.Vec3{}).DistanceField()
+	d, ok := df.Distance(mat.Vec3{0.15, 0.15, 0.15})
+	if !ok {
+		t.Fatal("Distance in the grid must be available")
+	}
+	if !math.IsInf(float64(d), 1) {
+		t.Errorf("Expected +Inf, got %f", d)
+	}
+}
+
+func BenchmarkVoxelGrid_DistanceField(b *testing.B) {
+	v := New(0.1, [3]int{100, 100, 50}, mat.Vec3{})
+	for i := 0; i < 1000; i++ {
+		a, _ := v.AddrByPosInt([3]int{rand.Intn(100), rand.Intn(100), rand.Intn(50)})
+		v.AddByAddr(a, i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		v.DistanceField()
+	}
+}
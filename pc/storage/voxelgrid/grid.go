@@ -0,0 +1,44 @@
+package voxelgrid
+
+import (
+	"github.com/seqsense/pcgol/mat"
+)
+
+// Grid is a common interface of the voxel grid storages.
+// Voxel at integer position i is centered at Origin() + i * Resolution().
+type Grid interface {
+	Resolution() float32
+	Origin() mat.Vec3
+	MinMax() (min, max mat.Vec3)
+	// PosIntMinMax returns the range of the integer positions of the voxels which may have points.
+	PosIntMinMax() (min, max [3]int)
+	Add(p mat.Vec3, index int) bool
+	Get(p mat.Vec3) []int
+	PosInt(p mat.Vec3) ([3]int, bool)
+	GetByPosInt(p [3]int) []int
+}
+
+var (
+	_ Grid = &VoxelGrid{}
+	_ Grid = &SparseVoxelGrid{}
+)
+
+// PosSorter sorts the integer positions of the voxels in lexicographical order.
+type PosSorter [][3]int
+
+func (s PosSorter) Len() int {
+	return len(s)
+}
+
+func (s PosSorter) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+
+func (s PosSorter) Less(i, j int) bool {
+	for k := 0; k < 3; k++ {
+		if s[i][k] != s[j][k] {
+			return s[i][k] < s[j][k]
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package voxelgrid
+
+import (
+	"math"
+
+	"github.com/seqsense/pcgol/mat"
+	"github.com/seqsense/pcgol/pc"
+)
+
+// Traverse visits the voxels along the line segment from p0 to p1
+// using Amanatides-Woo 3D DDA algorithm.
+// Voxel at integer position i is centered at origin + i * resolution.
+// fn is called for each voxel in order from p0 including both ends.
+// Traversal is stopped if fn returns false.
+// No voxel is visited if p0 or p1 has non-finite coordinate.
+func Traverse(origin mat.Vec3, resolution float32, p0, p1 mat.Vec3, fn func(pos [3]int) bool) {
+	if !p0.IsFinite() || !p1.IsFinite() {
+		return
+	}
+	resInv := 1 / float64(resolution)
+	var (
+		cur, end, step [3]int
+		tMax, tDelta   [3]float64
+		remaining      int
+	)
+	for i := 0; i < 3; i++ {
+		g0 := float64(p0[i]-origin[i])*resInv + 0.5
+		g1 := float64(p1[i]-origin[i])*resInv + 0.5
+		cur[i] = int(math.Floor(g0))
+		end[i] = int(math.Floor(g1))
+		d := g1 - g0
+		switch {
+		case d > 0:
+			step[i] = 1
+			tMax[i] = (float64(cur[i]+1) - g0) / d
+			tDelta[i] = 1 / d
+		case d < 0:
+			step[i] = -1
+			tMax[i] = (float64(cur[i]) - g0) / d
+			tDelta[i] = -1 / d
+		default:
+			tMax[i] = math.Inf(1)
+		}
+		if n := end[i] - cur[i]; n > 0 {
+			remaining += n
+		} else {
+			remaining -= n
+		}
+	}
+
+	if !fn(cur) {
+		return
+	}
+	for ; remaining > 0; remaining-- {
+		// Step along the axis with the nearest voxel boundary.
+		// Axes which already reached the end voxel are skipped
+		// to avoid overshoot caused by the rounding error.
+		axis := -1
+		for i := 0; i < 3; i++ {
+			if cur[i] == end[i] {
+				continue
+			}
+			if axis < 0 || tMax[i] < tMax[axis] {
+				axis = i
+			}
+		}
+		cur[axis] += step[axis]
+		tMax[axis] += tDelta[axis]
+		if !fn(cur) {
+			return
+		}
+	}
+}
+
+// Ray returns the integer positions of the voxels along the line segment from p0 to p1.
+func Ray(g Grid, p0, p1 mat.Vec3) [][3]int {
+	var out [][3]int
+	Traverse(g.Origin(), g.Resolution(), p0, p1, func(pos [3]int) bool {
+		out = append(out, pos)
+		return true
+	})
+	return out
+}
+
+// CountPassThrough counts the number of the rays passed through each voxel.
+// Rays are casted from origin to each point in ra.
+// Voxels containing the end points are not counted as passed through.
+func CountPassThrough(g Grid, origin mat.Vec3, ra pc.Vec3RandomAccessor) map[[3]int]int {
+	cnt := make(map[[3]int]int)
+	for i := 0; i < ra.Len(); i++ {
+		var prev [3]int
+		var started bool
+		Traverse(g.Origin(), g.Resolution(), origin, ra.Vec3At(i), func(pos [3]int) bool {
+			if started {
+				cnt[prev]++
+			}
+			prev, started = pos, true
+			return true
+		})
+	}
+	return cnt
+}
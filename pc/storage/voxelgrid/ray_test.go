@@ -0,0 +1,120 @@
+package voxelgrid
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/seqsense/pcgol/mat"
+	"github.com/seqsense/pcgol/pc"
+)
+
+func TestTraverse(t *testing.T) {
+	nan := float32(math.NaN())
+	inf := float32(math.Inf(1))
+	testCases := map[string]struct {
+		p0, p1   mat.Vec3
+		expected [][3]int
+	}{
+		"SameVoxel": {
+			p0:       mat.Vec3{0.1, 0.1, 0.1},
+			p1:       mat.Vec3{-0.2, 0.3, 0},
+			expected: [][3]int{{0, 0, 0}},
+		},
+		"AlongX": {
+			p0:       mat.Vec3{0, 0, 0},
+			p1:       mat.Vec3{3.2, 0, 0},
+			expected: [][3]int{{0, 0, 0}, {1, 0, 0}, {2, 0, 0}, {3, 0, 0}},
+		},
+		"NegativeY": {
+			p0:       mat.Vec3{0, 0.2, 0},
+			p1:       mat.Vec3{0, -2.1, 0},
+			expected: [][3]int{{0, 0, 0}, {0, -1, 0}, {0, -2, 0}},
+		},
+		"Diagonal2D": {
+			p0:       mat.Vec3{0, 0, 0},
+			p1:       mat.Vec3{2, 1.2, 0},
+			expected: [][3]int{{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {2, 1, 0}},
+		},
+		"Diagonal3D": {
+			p0: mat.Vec3{0.1, 0, -0.1},
+			p1: mat.Vec3{-1.9, 1, 0.9},
+			expected: [][3]int{
+				{0, 0, 0}, {-1, 0, 0}, {-1, 1, 0}, {-1, 1, 1}, {-2, 1, 1},
+			},
+		},
+		"NaNEnd": {
+			p0: mat.Vec3{0, 0, 0},
+			p1: mat.Vec3{1, nan, 0},
+		},
+		"InfStart": {
+			p0: mat.Vec3{0, 0, -inf},
+			p1: mat.Vec3{1, 0, 0},
+		},
+	}
+	for name, tt := range testCases {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			g := NewSparse(1, mat.Vec3{})
+			if voxels := Ray(g, tt.p0, tt.p1); !reflect.DeepEqual(tt.expected, voxels) {
+				t.Errorf("Expected %v, got %v", tt.expected, voxels)
+			}
+		})
+	}
+
+	t.Run("Stop", func(t *testing.T) {
+		var n int
+		Traverse(mat.Vec3{}, 1, mat.Vec3{}, mat.Vec3{10, 0, 0}, func(pos [3]int) bool {
+			n++
+			return pos[0] < 2
+		})
+		if n != 3 {
+			t.Errorf("Expected to be stopped after 3 voxels, visited %d", n)
+		}
+	})
+	t.Run("Connected", func(t *testing.T) {
+		origin := mat.Vec3{0.3, -0.2, 0.1}
+		p0, p1 := mat.Vec3{-1.23, 4.56, 7.89}, mat.Vec3{9.87, -6.54, 3.21}
+		var prev [3]int
+		var started bool
+		Traverse(origin, 0.1, p0, p1, func(pos [3]int) bool {
+			if started {
+				var d int
+				for i := range pos {
+					if pos[i] != prev[i] {
+						d++
+					}
+				}
+				if d != 1 {
+					t.Fatalf("Voxels %v and %v are not face-connected", prev, pos)
+				}
+			}
+			prev, started = pos, true
+			return true
+		})
+		g := NewSparse(0.1, origin)
+		if end, _ := g.PosInt(p1); prev != end {
+			t.Errorf("Expected last voxel: %v, got: %v", end, prev)
+		}
+	})
+}
+
+func TestCountPassThrough(t *testing.T) {
+	v := New(1, [3]int{8, 8, 8}, mat.Vec3{})
+	ra := pc.Vec3Slice{
+		{3, 0, 0},
+		{2, 0, 0},
+		{0, 2, 0},
+		{float32(math.NaN()), 0, 0},
+	}
+	cnt := CountPassThrough(v, mat.Vec3{}, ra)
+	expected := map[[3]int]int{
+		{0, 0, 0}: 3,
+		{1, 0, 0}: 2,
+		{2, 0, 0}: 1,
+		{0, 1, 0}: 1,
+	}
+	if !reflect.DeepEqual(expected, cnt) {
+		t.Errorf("Expected %v, got %v", expected, cnt)
+	}
+}
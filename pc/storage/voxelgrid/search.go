@@ -0,0 +1,235 @@
+package voxelgrid
+
+import (
+	"math"
+	"sort"
+
+	"github.com/seqsense/pcgol/mat"
+	"github.com/seqsense/pcgol/pc"
+	"github.com/seqsense/pcgol/pc/storage"
+)
+
+// Search implements storage.Search on the voxel grid
+// by scanning the voxels around the query point.
+type Search struct {
+	pc.Vec3RandomAccessor
+	grid  Grid
+	dense *VoxelGrid
+}
+
+// NewSearch creates Search.
+// Indice stored in the grid must correspond to the points in ra.
+func NewSearch(g Grid, ra pc.Vec3RandomAccessor) *Search {
+	dense, _ := g.(*VoxelGrid)
+	return &Search{
+		Vec3RandomAccessor: ra,
+		grid:               g,
+		dense:              dense,
+	}
+}
+
+// Search returns storage.Search view of the grid.
+// Indice stored in the grid must correspond to the points in ra.
+func (v *VoxelGrid) Search(ra pc.Vec3RandomAccessor) *Search {
+	return NewSearch(v, ra)
+}
+
+// Search returns storage.Search view of the grid.
+// Indice stored in the grid must correspond to the points in ra.
+func (v *SparseVoxelGrid) Search(ra pc.Vec3RandomAccessor) *Search {
+	return NewSearch(v, ra)
+}
+
+// posInt returns integer position of the voxel without bound check.
+func (s *Search) posInt(p mat.Vec3) [3]int {
+	f := s.posFloor(p)
+	return [3]int{int(f[0]), int(f[1]), int(f[2])}
+}
+
+func (s *Search) posFloor(p mat.Vec3) [3]float64 {
+	pos := p.Sub(s.grid.Origin())
+	resInv := 1 / s.grid.Resolution()
+	var out [3]float64
+	for i := range pos {
+		out[i] = math.Floor(float64(pos[i]*resInv + 0.5))
+	}
+	return out
+}
+
+// posIntRange returns the range of the voxels overlapping with the sphere.
+func (s *Search) posIntRange(p mat.Vec3, r float32) (min, max [3]int, ok bool) {
+	return s.boxPosIntRange(p.Sub(mat.Vec3{r, r, r}), p.Add(mat.Vec3{r, r, r}))
+}
+
+// boxPosIntRange returns the range of the voxels overlapping with the box.
+// It returns false if the range doesn't overlap with the grid.
+func (s *Search) boxPosIntRange(bMin, bMax mat.Vec3) (min, max [3]int, ok bool) {
+	gMin, gMax := s.grid.PosIntMinMax()
+	// Clamp before converting to int since the box may be infinite.
+	fMin, fMax := s.posFloor(bMin), s.posFloor(bMax)
+	for i := range min {
+		if fMin[i] < float64(gMin[i]) {
+			fMin[i] = float64(gMin[i])
+		}
+		if fMax[i] == float64(gMin[i]-1) {
+			// VoxelGrid rounds the position toward zero,
+			// so the first voxel may have the points on its lower side.
+			fMax[i] = float64(gMin[i])
+		}
+		if fMax[i] > float64(gMax[i]) {
+			fMax[i] = float64(gMax[i])
+		}
+		if !(fMin[i] <= fMax[i]) {
+			return min, max, false
+		}
+		min[i], max[i] = int(fMin[i]), int(fMax[i])
+	}
+	return min, max, true
+}
+
+func (s *Search) Nearest(p mat.Vec3, maxRange float32) storage.Neighbor {
+	nn := storage.Neighbor{ID: -1, DistSq: maxRange * maxRange}
+
+	gMin, gMax := s.grid.PosIntMinMax()
+	for i := range gMin {
+		if gMin[i] > gMax[i] {
+			return nn
+		}
+	}
+	c := s.posInt(p)
+	res := s.grid.Resolution()
+
+	// Start from the ring touching the grid.
+	var r0 int
+	// Points in the voxels at the ring r are farther than (r - slack) * resolution.
+	slack := 1
+	for i := range c {
+		if d := gMin[i] - c[i]; d > r0 {
+			r0 = d
+		}
+		if d := c[i] - gMax[i]; d > r0 {
+			r0 = d
+		}
+		if c[i] < gMin[i] {
+			// VoxelGrid rounds the position toward zero,
+			// so the first voxel may have the points on its lower side.
+			slack = 2
+		}
+	}
+
+	check := func(pos [3]int) {
+		var ids []int
+		if s.dense != nil {
+			// scanRing always gives the position inside the grid.
+			ids = s.dense.voxel[pos[0]+(pos[1]+pos[2]*s.dense.size[1])*s.dense.size[0]]
+		} else {
+			ids = s.grid.GetByPosInt(pos)
+		}
+		for _, id := range ids {
+			if dsq := s.Vec3At(id).Sub(p).NormSq(); dsq < nn.DistSq {
+				nn.ID, nn.DistSq = id, dsq
+			}
+		}
+	}
+	for r := r0; ; r++ {
+		if d := float32(r-slack) * res; d > 0 && d*d >= nn.DistSq {
+			break
+		}
+		s.scanRing(c, r, gMin, gMax, check)
+
+		covered := true
+		for i := range c {
+			if c[i]-r > gMin[i] || c[i]+r < gMax[i] {
+				covered = false
+			}
+		}
+		if covered {
+			break
+		}
+	}
+	return nn
+}
+
+// scanRing calls fn for each voxel whose Chebyshev distance from c is r.
+func (s *Search) scanRing(c [3]int, r int, gMin, gMax [3]int, fn func([3]int)) {
+	var min, max [3]int
+	for i := range c {
+		min[i], max[i] = c[i]-r, c[i]+r
+		if min[i] < gMin[i] {
+			min[i] = gMin[i]
+		}
+		if max[i] > gMax[i] {
+			max[i] = gMax[i]
+		}
+	}
+	for x := min[0]; x <= max[0]; x++ {
+		onX := x == c[0]-r || x == c[0]+r
+		for y := min[1]; y <= max[1]; y++ {
+			if onX || y == c[1]-r || y == c[1]+r {
+				for z := min[2]; z <= max[2]; z++ {
+					fn([3]int{x, y, z})
+				}
+				continue
+			}
+			if z := c[2] - r; gMin[2] <= z && z <= gMax[2] {
+				fn([3]int{x, y, z})
+			}
+			if z := c[2] + r; r > 0 && gMin[2] <= z && z <= gMax[2] {
+				fn([3]int{x, y, z})
+			}
+		}
+	}
+}
+
+func (s *Search) Range(p mat.Vec3, maxRange float32) []storage.Neighbor {
+	neighbors := []storage.Neighbor{}
+	min, max, ok := s.posIntRange(p, maxRange)
+	if !ok {
+		return neighbors
+	}
+	maxRangeSq := maxRange * maxRange
+	add := func(ids []int) {
+		for _, id := range ids {
+			if dsq := s.Vec3At(id).Sub(p).NormSq(); dsq < maxRangeSq {
+				neighbors = append(neighbors, storage.Neighbor{ID: id, DistSq: dsq})
+			}
+		}
+	}
+	if s.dense != nil {
+		// Directly access the voxels since the range is already clamped.
+		size := s.dense.size
+		for z := min[2]; z <= max[2]; z++ {
+			for y := min[1]; y <= max[1]; y++ {
+				addr := min[0] + (y+z*size[1])*size[0]
+				for x := min[0]; x <= max[0]; x++ {
+					add(s.dense.voxel[addr])
+					addr++
+				}
+			}
+		}
+	} else {
+		for x := min[0]; x <= max[0]; x++ {
+			for y := min[1]; y <= max[1]; y++ {
+				for z := min[2]; z <= max[2]; z++ {
+					add(s.grid.GetByPosInt([3]int{x, y, z}))
+				}
+			}
+		}
+	}
+	sort.Sort(neighborSorter(neighbors))
+	return neighbors
+}
+
+type neighborSorter []storage.Neighbor
+
+func (ns neighborSorter) Len() int {
+	return len(ns)
+}
+
+func (ns neighborSorter) Swap(i, j int) {
+	ns[i], ns[j] = ns[j], ns[i]
+}
+
+func (ns neighborSorter) Less(i, j int) bool {
+	return ns[i].DistSq < ns[j].DistSq
+}
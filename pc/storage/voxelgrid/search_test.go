@@ -0,0 +1,207 @@
+package voxelgrid
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/seqsense/pcgol/mat"
+	"github.com/seqsense/pcgol/pc"
+	"github.com/seqsense/pcgol/pc/storage"
+	"github.com/seqsense/pcgol/pc/storage/kdtree"
+	"github.com/seqsense/pcgol/pc/storage/storagetest"
+)
+
+var _ storage.Search = &Search{} // Search must implement storage.Search
+
+func naiveRange(ra pc.Vec3RandomAccessor, p mat.Vec3, maxRange float32) []storage.Neighbor {
+	neighbors := []storage.Neighbor{}
+	for i := 0; i < ra.Len(); i++ {
+		if dsq := ra.Vec3At(i).Sub(p).NormSq(); dsq < maxRange*maxRange {
+			neighbors = append(neighbors, storage.Neighbor{ID: i, DistSq: dsq})
+		}
+	}
+	sortNeighbors(neighbors)
+	return neighbors
+}
+
+func sortNeighbors(ns []storage.Neighbor) {
+	sort.Slice(ns, func(i, j int) bool {
+		if ns[i].DistSq == ns[j].DistSq {
+			return ns[i].ID < ns[j].ID
+		}
+		return ns[i].DistSq < ns[j].DistSq
+	})
+}
+
+func TestSearch(t *testing.T) {
+	const (
+		nPoints = 500
+		width   = 4.0
+	)
+	ra := make(pc.Vec3Slice, nPoints)
+	for i := range ra {
+		ra[i] = mat.Vec3{rand.Float32() * width, rand.Float32() * width, rand.Float32() * width}
+	}
+
+	grids := map[string]Grid{
+		"VoxelGrid":       New(0.25, [3]int{17, 17, 17}, mat.Vec3{}),
+		"SparseVoxelGrid": NewSparse(0.25, mat.Vec3{}),
+	}
+	for name, g := range grids {
+		g := g
+		t.Run(name, func(t *testing.T) {
+			for i, p := range ra {
+				if !g.Add(p, i) {
+					t.Fatalf("Failed to add point %d", i)
+				}
+			}
+			s := NewSearch(g, ra)
+			for i := 0; i < 200; i++ {
+				// Query points including outside of the grid
+				p := mat.Vec3{
+					rand.Float32()*width*2 - width/2,
+					rand.Float32()*width*2 - width/2,
+					rand.Float32()*width*2 - width/2,
+				}
+				maxRange := rand.Float32() * width / 2
+
+				expected := naiveRange(ra, p, maxRange)
+				neighbors := s.Range(p, maxRange)
+				for j := 1; j < len(neighbors); j++ {
+					if neighbors[j].DistSq < neighbors[j-1].DistSq {
+						t.Fatal("Neighbors are not sorted")
+					}
+				}
+				sortNeighbors(neighbors)
+				if !reflect.DeepEqual(expected, neighbors) {
+					t.Fatalf("Range %v %f: Expected %v, got %v", p, maxRange, expected, neighbors)
+				}
+
+				nn := s.Nearest(p, maxRange)
+				if len(expected) == 0 {
+					if nn.ID != -1 || nn.DistSq != maxRange*maxRange {
+						t.Fatalf("Nearest %v %f: Expected no neighbor, got %v", p, maxRange, nn)
+					}
+				} else if nn.DistSq != expected[0].DistSq {
+					t.Fatalf("Nearest %v %f: Expected %v, got %v", p, maxRange, expected[0], nn)
+				}
+			}
+		})
+	}
+}
+
+func TestSearch_empty(t *testing.T) {
+	s := NewSearch(NewSparse(0.1, mat.Vec3{}), pc.Vec3Slice{})
+	if nn := s.Nearest(mat.Vec3{}, 1); nn.ID != -1 || nn.DistSq != 1 {
+		t.Errorf("Expected no neighbor, got %v", nn)
+	}
+	if ns := s.Range(mat.Vec3{}, 1); len(ns) != 0 {
+		t.Errorf("Expected no neighbor, got %v", ns)
+	}
+}
+
+func TestVoxelGrid_Search(t *testing.T) {
+	ra := pc.Vec3Slice{
+		{0, 0, 0},
+		{0.1, 0, 0},
+		{0.5, 0.5, 0.5},
+		{-0.12, 0, 0}, // stored in the first voxel
+	}
+	v := New(0.1, [3]int{8, 8, 8}, mat.Vec3{})
+	for i, p := range ra {
+		v.Add(p, i)
+	}
+	var s storage.Search = v.Search(ra)
+
+	expected := []storage.Neighbor{
+		{ID: 0, DistSq: 0.01 * 0.01},
+		{ID: 1, DistSq: 0.09 * 0.09},
+		{ID: 3, DistSq: 0.13 * 0.13},
+	}
+	neighbors := s.Range(mat.Vec3{0.01, 0, 0}, 0.2)
+	if len(neighbors) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, neighbors)
+	}
+	for i := range expected {
+		if d := neighbors[i].DistSq - expected[i].DistSq; neighbors[i].ID != expected[i].ID || d < -1e-6 || 1e-6 < d {
+			t.Errorf("Expected %v, got %v", expected, neighbors)
+		}
+	}
+	if nn := s.Nearest(mat.Vec3{-0.25, 0, 0}, 0.2); nn.ID != 3 {
+		t.Errorf("Expected nearest: 3, got: %v", nn)
+	}
+	if nn := s.Nearest(mat.Vec3{0.45, 0.5, 0.5}, 0.2); nn.ID != 2 {
+		t.Errorf("Expected nearest: 2, got: %v", nn)
+	}
+}
+
+func BenchmarkSearch(b *testing.B) {
+	const (
+		nPoints  = 100000
+		width    = 10.0
+		maxRange = 0.2
+	)
+	ra := make(pc.Vec3Slice, nPoints)
+	for i := range ra {
+		ra[i] = mat.Vec3{rand.Float32() * width, rand.Float32() * width, rand.Float32() * width}
+	}
+	targets := make(pc.Vec3Slice, 100)
+	for i := range targets {
+		targets[i] = mat.Vec3{rand.Float32() * width, rand.Float32() * width, rand.Float32() * width}
+	}
+
+	v := New(0.2, [3]int{51, 51, 51}, mat.Vec3{})
+	sv := NewSparse(0.2, mat.Vec3{})
+	for i, p := range ra {
+		v.Add(p, i)
+		sv.Add(p, i)
+	}
+	searches := map[string]storage.Search{
+		"VoxelGrid":       v.Search(ra),
+		"SparseVoxelGrid": sv.Search(ra),
+		"KDTree":          kdtree.New(ra),
+	}
+	for name, s := range searches {
+		s := s
+		b.Run(name, func(b *testing.B) {
+			b.Run("Nearest", func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					_ = s.Nearest(targets[i%len(targets)], maxRange)
+				}
+			})
+			b.Run("Range", func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					_ = s.Range(targets[i%len(targets)], maxRange)
+				}
+			})
+		})
+	}
+}
+
+func TestSearch_conformance(t *testing.T) {
+	origin := mat.Vec3{-storagetest.Extent, -storagetest.Extent, -storagetest.Extent}
+	t.Run("VoxelGrid", func(t *testing.T) {
+		storagetest.TestSearch(t, func(t *testing.T, ra pc.Vec3RandomAccessor) storage.Search {
+			v := New(0.5, [3]int{21, 21, 21}, origin)
+			for i := 0; i < ra.Len(); i++ {
+				if !v.Add(ra.Vec3At(i), i) {
+					t.Fatalf("Failed to add point %d", i)
+				}
+			}
+			return v.Search(ra)
+		})
+	})
+	t.Run("SparseVoxelGrid", func(t *testing.T) {
+		storagetest.TestSearch(t, func(t *testing.T, ra pc.Vec3RandomAccessor) storage.Search {
+			v := NewSparse(0.5, origin)
+			for i := 0; i < ra.Len(); i++ {
+				if !v.Add(ra.Vec3At(i), i) {
+					t.Fatalf("Failed to add point %d", i)
+				}
+			}
+			return v.Search(ra)
+		})
+	})
+}
@@ -0,0 +1,121 @@
+package voxelgrid
+
+import (
+	"math"
+
+	"github.com/seqsense/pcgol/mat"
+)
+
+// SparseVoxelGrid is a hash map based voxel grid which has unbounded extent.
+type SparseVoxelGrid struct {
+	voxel         map[[3]int][]int
+	min, max      [3]int
+	origin        mat.Vec3
+	resolution    float32
+	resolutionInv float32
+}
+
+func NewSparse(resolution float32, origin mat.Vec3) *SparseVoxelGrid {
+	return &SparseVoxelGrid{
+		voxel:         make(map[[3]int][]int),
+		origin:        origin,
+		resolution:    resolution,
+		resolutionInv: 1 / resolution,
+	}
+}
+
+// MinMax returns the bounding box of the voxels which have points.
+func (v *SparseVoxelGrid) MinMax() (min, max mat.Vec3) {
+	if len(v.voxel) == 0 {
+		return v.origin, v.origin
+	}
+	for i := range min {
+		min[i] = v.origin[i] + float32(v.min[i])*v.resolution
+		max[i] = v.origin[i] + float32(v.max[i]+1)*v.resolution
+	}
+	return min, max
+}
+
+func (v *SparseVoxelGrid) PosIntMinMax() (min, max [3]int) {
+	if len(v.voxel) == 0 {
+		return [3]int{}, [3]int{-1, -1, -1}
+	}
+	return v.min, v.max
+}
+
+func (v *SparseVoxelGrid) Resolution() float32 {
+	return v.resolution
+}
+
+func (v *SparseVoxelGrid) Origin() mat.Vec3 {
+	return v.origin
+}
+
+func (v *SparseVoxelGrid) Add(p mat.Vec3, index int) bool {
+	pos, ok := v.PosInt(p)
+	if !ok {
+		return false
+	}
+	v.AddByPosInt(pos, index)
+	return true
+}
+
+func (v *SparseVoxelGrid) AddByPosInt(p [3]int, index int) {
+	if len(v.voxel) == 0 {
+		v.min, v.max = p, p
+	}
+	for i := range p {
+		if p[i] < v.min[i] {
+			v.min[i] = p[i]
+		}
+		if p[i] > v.max[i] {
+			v.max[i] = p[i]
+		}
+	}
+	v.voxel[p] = append(v.voxel[p], index)
+}
+
+func (v *SparseVoxelGrid) Get(p mat.Vec3) []int {
+	pos, ok := v.PosInt(p)
+	if !ok {
+		return nil
+	}
+	return v.voxel[pos]
+}
+
+func (v *SparseVoxelGrid) GetByPosInt(p [3]int) []int {
+	return v.voxel[p]
+}
+
+// PosInt returns integer position of the voxel.
+// It returns false only if the point has non-finite coordinate.
+func (v *SparseVoxelGrid) PosInt(p mat.Vec3) ([3]int, bool) {
+	pos := p.Sub(v.origin)
+	var out [3]int
+	for i := range pos {
+		f := math.Floor(float64(pos[i]*v.resolutionInv + 0.5))
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return [3]int{}, false
+		}
+		out[i] = int(f)
+	}
+	return out, true
+}
+
+// Len returns the number of the voxels which have points.
+func (v *SparseVoxelGrid) Len() int {
+	return len(v.voxel)
+}
+
+// Indice returns all indice stored in the grid in unspecified order.
+func (v *SparseVoxelGrid) Indice() []int {
+	out := make([]int, 0, 1024)
+	for _, g := range v.voxel {
+		out = append(out, g...)
+	}
+	return out
+}
+
+func (v *SparseVoxelGrid) Reset() {
+	v.voxel = make(map[[3]int][]int)
+}
@@ -0,0 +1,74 @@
+package voxelgrid
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/seqsense/pcgol/mat"
+)
+
+func TestSparseVoxelGrid(t *testing.T) {
+	points := []mat.Vec3{
+		{-2, 0, 0},
+		{2, 5, 10},
+		{2.01, 5, 10},
+		{2 + 1, 5 + 1, 10 + 1},
+		{2 + 3.21, 5, 10},
+		{-1000, 5, 10},
+	}
+
+	v := NewSparse(0.05, mat.Vec3{2, 5, 10})
+	for i, p := range points {
+		if !v.Add(p, i) {
+			t.Errorf("Point %d should be added", i)
+		}
+	}
+	if v.Len() != 5 {
+		t.Errorf("Expected number of voxels: 5, got: %d", v.Len())
+	}
+
+	testCases := map[int][]int{
+		0: {0},
+		1: {1, 2},
+		2: {1, 2},
+		3: {3},
+		4: {4},
+		5: {5},
+	}
+	for i, expected := range testCases {
+		if ids := v.Get(points[i]); !reflect.DeepEqual(expected, ids) {
+			t.Errorf("Expected %v for point %d, got %v", expected, i, ids)
+		}
+		pos, ok := v.PosInt(points[i])
+		if !ok {
+			t.Fatalf("PosInt of point %d should be valid", i)
+		}
+		if ids := v.GetByPosInt(pos); !reflect.DeepEqual(expected, ids) {
+			t.Errorf("Expected %v for point %d, got %v", expected, i, ids)
+		}
+	}
+
+	if pos, _ := v.PosInt(mat.Vec3{2 - 0.04, 5, 10}); pos != [3]int{-1, 0, 0} {
+		t.Errorf("Expected position: %v, got: %v", [3]int{-1, 0, 0}, pos)
+	}
+
+	min, max := v.PosIntMinMax()
+	if expected := [3]int{-20040, -100, -200}; min != expected {
+		t.Errorf("Expected min: %v, got: %v", expected, min)
+	}
+	if expected := [3]int{64, 20, 20}; max != expected {
+		t.Errorf("Expected max: %v, got: %v", expected, max)
+	}
+
+	indice := v.Indice()
+	sort.Ints(indice)
+	if expected := []int{0, 1, 2, 3, 4, 5}; !reflect.DeepEqual(expected, indice) {
+		t.Errorf("Expected indice: %v, got: %v", expected, indice)
+	}
+
+	v.Reset()
+	if v.Len() != 0 || v.Get(points[1]) != nil {
+		t.Error("Grid should be empty after Reset")
+	}
+}
@@ -0,0 +1,50 @@
+package voxelgrid
+
+import (
+	"sort"
+
+	"github.com/seqsense/pcgol/mat"
+	"github.com/seqsense/pcgol/pc/storage"
+)
+
+// Inside returns indice of the points inside the volume in ascending order.
+// Voxels which don't intersect with the volume are skipped.
+func (s *Search) Inside(v storage.Volume) []int {
+	out := []int{}
+	vMin, vMax := v.Bounds()
+	min, max, ok := s.boxPosIntRange(vMin, vMax)
+	if !ok {
+		return out
+	}
+	gMin, _ := s.grid.PosIntMinMax()
+	origin := s.grid.Origin()
+	res := s.grid.Resolution()
+
+	for x := min[0]; x <= max[0]; x++ {
+		for y := min[1]; y <= max[1]; y++ {
+			for z := min[2]; z <= max[2]; z++ {
+				pos := [3]int{x, y, z}
+				var bMin, bMax mat.Vec3
+				for i := range pos {
+					c := origin[i] + float32(pos[i])*res
+					bMin[i], bMax[i] = c-res/2, c+res/2
+					if pos[i] == gMin[i] {
+						// VoxelGrid rounds the position toward zero,
+						// so the first voxel may have the points on its lower side.
+						bMin[i] -= res
+					}
+				}
+				if !v.IntersectsBox(bMin, bMax) {
+					continue
+				}
+				for _, id := range s.grid.GetByPosInt(pos) {
+					if v.Contains(s.Vec3At(id)) {
+						out = append(out, id)
+					}
+				}
+			}
+		}
+	}
+	sort.Ints(out)
+	return out
+}
@@ -0,0 +1,64 @@
+package voxelgrid
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/seqsense/pcgol/mat"
+	"github.com/seqsense/pcgol/pc"
+	"github.com/seqsense/pcgol/pc/storage"
+)
+
+var _ storage.VolumeSearch = &Search{} // Search must implement storage.VolumeSearch
+
+func TestSearch_Inside(t *testing.T) {
+	const (
+		nPoints = 1000
+		width   = 10.0
+	)
+	ra := make(pc.Vec3Slice, nPoints)
+	for i := range ra {
+		ra[i] = mat.Vec3{rand.Float32() * width, rand.Float32() * width, rand.Float32() * width}
+	}
+
+	view := mat.Rotate(0, 1, 0, -math.Pi/2).Mul(mat.Translate(-5, -5, -5))
+	volumes := map[string]storage.Volume{
+		"AABB": storage.AABB{Min: mat.Vec3{1, 2, 3}, Max: mat.Vec3{4, 6, 8}},
+		"OrientedBox": storage.NewOrientedBox(
+			mat.Translate(5, 5, 5).Mul(mat.Rotate(1, 1, 0, 0.5)),
+			mat.Vec3{4, 2, 3},
+		),
+		"Frustum": storage.NewFrustum(mat.Perspective(0.8, 1.5, 0.5, 4).Mul(view)),
+		"Predicate": storage.NewPredicate(mat.Vec3{-20, -20, -20}, mat.Vec3{20, 20, 20}, func(p mat.Vec3) bool {
+			return p.Sub(mat.Vec3{5, 5, 5}).NormSq() < 9
+		}),
+		"Empty": storage.AABB{Min: mat.Vec3{20, 20, 20}, Max: mat.Vec3{21, 21, 21}},
+	}
+
+	grids := map[string]Grid{
+		"VoxelGrid":       New(0.5, [3]int{21, 21, 21}, mat.Vec3{}),
+		"SparseVoxelGrid": NewSparse(0.5, mat.Vec3{}),
+	}
+	for gName, g := range grids {
+		for i, p := range ra {
+			g.Add(p, i)
+		}
+		s := NewSearch(g, ra)
+		for name, v := range volumes {
+			v := v
+			t.Run(gName+"/"+name, func(t *testing.T) {
+				expected := []int{}
+				for i, p := range ra {
+					if v.Contains(p) {
+						expected = append(expected, i)
+					}
+				}
+				if ids := s.Inside(v); !reflect.DeepEqual(expected, ids) {
+					t.Errorf("Expected %v, got %v", expected, ids)
+				}
+			})
+		}
+	}
+}
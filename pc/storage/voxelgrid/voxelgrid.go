@@ -34,6 +34,14 @@ func (v *VoxelGrid) Resolution() float32 {
 	return v.resolution
 }
 
+func (v *VoxelGrid) Origin() mat.Vec3 {
+	return v.origin
+}
+
+func (v *VoxelGrid) PosIntMinMax() (min, max [3]int) {
+	return [3]int{}, [3]int{v.size[0] - 1, v.size[1] - 1, v.size[2] - 1}
+}
+
 func (v *VoxelGrid) Add(p mat.Vec3, index int) bool {
 	addr, ok := v.Addr(p)
 	if !ok {
@@ -61,6 +69,14 @@ func (v *VoxelGrid) GetByAddr(a int) []int {
 	return v.voxel[a]
 }
 
+func (v *VoxelGrid) GetByPosInt(p [3]int) []int {
+	addr, ok := v.AddrByPosInt(p)
+	if !ok {
+		return nil
+	}
+	return v.voxel[addr]
+}
+
 func (v *VoxelGrid) Addr(p mat.Vec3) (int, bool) {
 	pos := p.Sub(v.origin)
 	x := int(pos[0]*v.resolutionInv + 0.5)
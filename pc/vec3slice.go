@@ -18,3 +18,18 @@ func (v Vec3Slice) Vec3At(i int) mat.Vec3 {
 func (v Vec3Slice) RawIndexAt(i int) int {
 	return i
 }
+
+// Vec3dSlice wraps []mat.Vec3d and implements Vec3dRandomAccessor.
+type Vec3dSlice []mat.Vec3d
+
+func (v Vec3dSlice) Len() int {
+	return len(v)
+}
+
+func (v Vec3dSlice) Vec3dAt(i int) mat.Vec3d {
+	return v[i]
+}
+
+func (v Vec3dSlice) RawIndexAt(i int) int {
+	return i
+}